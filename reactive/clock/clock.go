@@ -0,0 +1,48 @@
+// Package clock abstracts the passing of time, so that Components
+// which animate or poll can be given a virtual clock in tests.
+// See zemn.me/reactive/tree/treetest.Clock.
+package clock // import "zemn.me/reactive/clock"
+
+import "time"
+
+// A Clock tells the time, and waits for it to pass.
+type Clock interface {
+	Now() time.Time
+
+	// After is like time.After.
+	After(d time.Duration) <-chan time.Time
+
+	// AfterFunc is like time.AfterFunc. Virtual Clocks may
+	// call f from the goroutine advancing them, which makes
+	// it a more predictable choice than After in tests.
+	AfterFunc(d time.Duration, f func()) Timer
+
+	// NewTicker is like time.NewTicker.
+	NewTicker(d time.Duration) Ticker
+}
+
+// A Timer is a call to AfterFunc.
+type Timer interface {
+	// Stop is like time.Timer.Stop.
+	Stop() bool
+}
+
+// A Ticker is like a time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the Clock of the time package.
+var Real Clock = real{}
+
+type real struct{}
+
+func (real) Now() time.Time                            { return time.Now() }
+func (real) After(d time.Duration) <-chan time.Time    { return time.After(d) }
+func (real) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+func (real) NewTicker(d time.Duration) Ticker          { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
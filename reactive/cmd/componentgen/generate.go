@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+const marker = "//componentgen"
+
+// A component is a struct marked for generation.
+type component struct {
+	typ     string
+	name    string
+	pointer bool
+
+	// the methods it already has
+	has map[string]bool
+
+	// the fields ShouldUpdate asks, and those
+	// it compares. Askers which can be nil are
+	// checked for it first.
+	askers   []string
+	nilable  map[string]bool
+	compared []string
+}
+
+// errorList is every problem found in a package.
+type errorList []error
+
+func (e errorList) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// generate returns the source of the file containing
+// the methods of the marked structs of the package in dir,
+// ignoring output, the file it will be written to.
+func generate(dir, output string) (src []byte, err error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return
+	}
+
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		if name == output {
+			continue
+		}
+
+		var f *ast.File
+		if f, err = parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments); err != nil {
+			return
+		}
+
+		files = append(files, f)
+	}
+
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+
+		// the package may not compile until its methods
+		// are generated, so errors are ignored; any left
+		// after generating will be caught by the compiler.
+		Error: func(error) {},
+	}
+
+	pkg, _ := conf.Check(bp.ImportPath, fset, files, info)
+
+	var errs errorList
+	var components []component
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+
+				doc := ts.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+
+				name, marked := markedName(doc)
+				if !marked {
+					continue
+				}
+
+				c, err := inspect(fset, pkg, info.Defs[ts.Name].(*types.TypeName), name)
+				if err != nil {
+					errs = append(errs, err...)
+					continue
+				}
+
+				components = append(components, c)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return format.Source(write(pkg.Name(), components))
+}
+
+// markedName reports whether doc contains the marker,
+// and the name given after it, if any.
+func markedName(doc *ast.CommentGroup) (name string, marked bool) {
+	if doc == nil {
+		return
+	}
+
+	for _, c := range doc.List {
+		rest := strings.TrimPrefix(c.Text, marker)
+		if rest == c.Text || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+
+		return strings.TrimSpace(rest), true
+	}
+
+	return
+}
+
+// inspect works out what to generate for the type obj.
+func inspect(fset *token.FileSet, pkg *types.Package, obj *types.TypeName, name string) (c component, errs errorList) {
+	fail := func(pos token.Pos, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", fset.Position(pos), fmt.Sprintf(format, args...)))
+	}
+
+	named := obj.Type().(*types.Named)
+	if named.TypeParams().Len() > 0 {
+		fail(obj.Pos(), "%s is marked %s, but generic types are not supported", obj.Name(), marker)
+		return
+	}
+
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		fail(obj.Pos(), "%s is marked %s, but is not a struct", obj.Name(), marker)
+		return
+	}
+
+	c = component{typ: obj.Name(), name: name, has: make(map[string]bool), nilable: make(map[string]bool)}
+	if c.name == "" {
+		c.name = obj.Name()
+	}
+
+	for i := 0; i < named.NumMethods(); i++ {
+		m := named.Method(i)
+		c.has[m.Name()] = true
+
+		if m.Name() == "Render" {
+			_, c.pointer = m.Type().(*types.Signature).Recv().Type().(*types.Pointer)
+		}
+	}
+
+	if !c.has["Render"] {
+		fail(obj.Pos(), "%s has no Render method; it must be written by hand", obj.Name())
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if f.Name() == "_" || reflect.StructTag(st.Tag(i)).Get("component") == "-" {
+			continue
+		}
+
+		switch {
+		case f.Type() == types.Typ[types.Invalid]:
+			fail(f.Pos(), "%s.%s: the type of the field could not be worked out", obj.Name(), f.Name())
+		case asks(pkg, f.Type()):
+			c.askers = append(c.askers, f.Name())
+
+			switch f.Type().Underlying().(type) {
+			case *types.Interface, *types.Pointer:
+				c.nilable[f.Name()] = true
+			}
+		case types.Comparable(f.Type()):
+			c.compared = append(c.compared, f.Name())
+		case !c.has["ShouldUpdate"]:
+			fail(f.Pos(),
+				"%s.%s: cannot generate ShouldUpdate, as %s can neither be compared nor asked to ShouldUpdate;"+
+					" tag the field `component:\"-\"` to ignore it, or write ShouldUpdate by hand",
+				obj.Name(), f.Name(), types.TypeString(f.Type(), types.RelativeTo(pkg)),
+			)
+		}
+	}
+
+	return
+}
+
+// asks reports whether t has a method ShouldUpdate(T) bool
+// which can be passed a t.
+func asks(pkg *types.Package, t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, pkg, "ShouldUpdate")
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	sig := fn.Type().(*types.Signature)
+	return sig.Params().Len() == 1 &&
+		sig.Results().Len() == 1 &&
+		types.Identical(sig.Results().At(0).Type().Underlying(), types.Typ[types.Bool]) &&
+		types.AssignableTo(t, sig.Params().At(0).Type())
+}
+
+// write writes the source of the methods of components.
+func write(pkg string, components []component) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by componentgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "import \"zemn.me/reactive/tree\"\n")
+
+	for _, c := range components {
+		recv, literal := c.typ, c.typ+"{}"
+		if c.pointer {
+			recv, literal = "*"+c.typ, "&"+c.typ+"{}"
+		}
+
+		fmt.Fprintf(&b, "\nvar _ tree.Component = %s\n\n", literal)
+
+		if !c.has["Name"] {
+			fmt.Fprintf(&b, "func (%s) Name() string { return %q }\n", recv, c.name)
+		}
+
+		if !c.has["Mount"] {
+			fmt.Fprintf(&b, "func (%s) Mount(tree.StateController) {}\n", recv)
+		}
+
+		if !c.has["Close"] {
+			fmt.Fprintf(&b, "func (%s) Close() {}\n", recv)
+		}
+
+		if !c.has["ShouldUpdate"] {
+			writeShouldUpdate(&b, c, recv)
+		}
+	}
+
+	return b.Bytes()
+}
+
+func writeShouldUpdate(b *bytes.Buffer, c component, recv string) {
+	fmt.Fprintf(b, "\nfunc (c %s) ShouldUpdate(old tree.Component) (bool, error) {\n", recv)
+	fmt.Fprintf(b, "o, ok := old.(%s)\n", recv)
+
+	if c.pointer {
+		fmt.Fprintf(b, "if !ok || o == nil {\n")
+	} else {
+		fmt.Fprintf(b, "if !ok {\n")
+	}
+
+	fmt.Fprintf(b, "return true, nil\n}\n\n")
+
+	for _, f := range c.askers {
+		if c.nilable[f] {
+			fmt.Fprintf(b, "if (c.%[1]s == nil) != (o.%[1]s == nil) || c.%[1]s != nil && c.%[1]s.ShouldUpdate(o.%[1]s) {\n", f)
+		} else {
+			fmt.Fprintf(b, "if c.%[1]s.ShouldUpdate(o.%[1]s) {\n", f)
+		}
+
+		fmt.Fprintf(b, "return true, nil\n}\n\n")
+	}
+
+	if len(c.compared) == 0 {
+		fmt.Fprintf(b, "return false, nil\n}\n")
+		return
+	}
+
+	differ := make([]string, len(c.compared))
+	for i, f := range c.compared {
+		differ[i] = fmt.Sprintf("c.%[1]s != o.%[1]s", f)
+	}
+
+	fmt.Fprintf(b, "return %s, nil\n}\n", strings.Join(differ, " ||\n"))
+}
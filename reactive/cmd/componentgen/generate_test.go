@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("generate", func() {
+	It("should generate the methods marked structs lack", func() {
+		src, err := generate("testdata/widgets", "components_gen.go")
+		Expect(err).ToNot(HaveOccurred())
+
+		// testdata/widgets/components_gen.go is known to compile
+		want, err := os.ReadFile("testdata/widgets/components_gen.go")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(string(src)).To(Equal(string(want)))
+	})
+
+	It("should explain what it cannot generate", func() {
+		_, err := generate("testdata/broken", "components_gen.go")
+
+		var errs errorList
+		Expect(err).To(BeAssignableToTypeOf(errs))
+		errs = err.(errorList)
+
+		Expect(errs).To(HaveLen(4))
+		Expect(errs[0].Error()).To(HavePrefix("testdata/broken/broken.go:8:2: Unsupported.Items:"))
+		Expect(errs[0].Error()).To(ContainSubstring("[]string can neither be compared nor asked to ShouldUpdate"))
+		Expect(errs[1].Error()).To(ContainSubstring("Unsupported.Lookup"))
+		Expect(errs[2].Error()).To(ContainSubstring("NotAStruct is marked //componentgen, but is not a struct"))
+		Expect(errs[3].Error()).To(ContainSubstring("NoRender has no Render method"))
+	})
+})
@@ -0,0 +1,65 @@
+/*
+Command componentgen writes the boilerplate methods of tree.Components.
+
+It is run by go generate, from a file in the package to generate for:
+
+	//go:generate go run zemn.me/reactive/cmd/componentgen
+
+It generates methods for each struct in the package whose documentation has a
+line starting //componentgen, optionally followed by the Name of the Component:
+
+	//componentgen fill
+	type Fill struct {
+		Cell
+		Canvas
+	}
+
+	func (f Fill) Render() ([]tree.Component, error) { ... }
+
+Each struct must have a hand-written Render method. componentgen writes whichever
+of Name, Mount, Close and ShouldUpdate it does not already have, along with an
+assertion that it is a tree.Component, to components_gen.go:
+
+  - Name returns the name given, or else the name of the type.
+  - Mount and Close do nothing.
+  - ShouldUpdate reports whether the old Component is of a different type,
+    or any of its fields differ. A field with a ShouldUpdate(T) bool method,
+    such as a term.Canvas, is instead asked whether it should update. Fields
+    tagged `component:"-"` are ignored.
+
+Fields which can neither be compared nor asked are errors.
+
+If Render has a pointer receiver, the methods are generated for the pointer.
+*/
+package main // import "zemn.me/reactive/cmd/componentgen"
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	output := flag.String("output", "components_gen.go", "the file to write, in the package directory")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	if err := do(dir, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "componentgen:", err)
+		os.Exit(1)
+	}
+}
+
+func do(dir, output string) (err error) {
+	src, err := generate(dir, output)
+	if err != nil {
+		return
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
@@ -0,0 +1,18 @@
+// Package broken has structs componentgen cannot generate for.
+package broken
+
+import "zemn.me/reactive/tree"
+
+//componentgen
+type Unsupported struct {
+	Items  []string
+	Lookup map[string]int
+}
+
+func (Unsupported) Render() ([]tree.Component, error) { return nil, nil }
+
+//componentgen
+type NotAStruct int
+
+//componentgen
+type NoRender struct{}
@@ -0,0 +1,44 @@
+// Code generated by componentgen; DO NOT EDIT.
+
+package widgets
+
+import "zemn.me/reactive/tree"
+
+var _ tree.Component = Label{}
+
+func (Label) Name() string               { return "Label" }
+func (Label) Mount(tree.StateController) {}
+func (Label) Close()                     {}
+
+func (c Label) ShouldUpdate(old tree.Component) (bool, error) {
+	o, ok := old.(Label)
+	if !ok {
+		return true, nil
+	}
+
+	if (c.Canvas == nil) != (o.Canvas == nil) || c.Canvas != nil && c.Canvas.ShouldUpdate(o.Canvas) {
+		return true, nil
+	}
+
+	return c.Text != o.Text, nil
+}
+
+var _ tree.Component = &List{}
+
+func (*List) Name() string               { return "list" }
+func (*List) Mount(tree.StateController) {}
+func (*List) Close()                     {}
+
+func (c *List) ShouldUpdate(old tree.Component) (bool, error) {
+	o, ok := old.(*List)
+	if !ok || o == nil {
+		return true, nil
+	}
+
+	return c.Title != o.Title, nil
+}
+
+var _ tree.Component = Custom{}
+
+func (Custom) Mount(tree.StateController) {}
+func (Custom) Close()                     {}
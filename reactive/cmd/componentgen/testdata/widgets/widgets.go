@@ -0,0 +1,36 @@
+// Package widgets is generated for by the componentgen tests.
+package widgets
+
+import "zemn.me/reactive/tree"
+
+type Canvas interface {
+	ShouldUpdate(c Canvas) bool
+}
+
+// A Label is named after its type.
+//
+//componentgen
+type Label struct {
+	Text string
+	Canvas
+}
+
+func (Label) Render() ([]tree.Component, error) { return nil, nil }
+
+//componentgen list
+type List struct {
+	Title string
+	Items []string `component:"-"`
+}
+
+func (*List) Render() ([]tree.Component, error) { return nil, nil }
+
+//componentgen
+type Custom struct{ Items []string }
+
+func (Custom) Name() string                              { return "custom" }
+func (Custom) ShouldUpdate(tree.Component) (bool, error) { return true, nil }
+func (Custom) Render() ([]tree.Component, error)         { return nil, nil }
+
+// Unmarked is ignored.
+type Unmarked struct{}
@@ -0,0 +1,179 @@
+package layout
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+
+	"zemn.me/debug"
+	"zemn.me/reactive/tree"
+)
+
+var _ tree.Component = &File{}
+
+// DefaultPoll is how often a File checks
+// for changes if its Poll is zero.
+const DefaultPoll = time.Second
+
+// A File is a Component which renders the layout document in a file,
+// reloading it when the file changes.
+//
+// If the reloaded Layout has the same Shape as the last, the new tree
+// is reconciled against the running one, so its Components keep their
+// state. Otherwise, the old tree is closed and the new one mounted.
+//
+// If the reloaded document has errors, they are returned from Render,
+// and so passed to the Mapper, until the file is fixed.
+//
+// Changes are found by checking the modification time and size of
+// the file every Poll, so a change is seen up to Poll after it is made.
+type File struct {
+	Registry *Registry
+	Path     string
+
+	// how often to check the file for changes;
+	// if zero, DefaultPoll.
+	Poll time.Duration
+
+	mu       sync.Mutex
+	layout   Layout
+	err      error
+	modified time.Time
+	size     int64
+	stop     chan struct{}
+
+	// whether the file could not be found
+	// at the last reload
+	missing bool
+}
+
+// NewFile returns a File rendering the layout document
+// at path, which must load without errors.
+func NewFile(r *Registry, path string) (f *File, err error) {
+	f = &File{Registry: r, Path: path}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	f.modified, f.size = info.ModTime(), info.Size()
+
+	if f.layout, err = r.ParseFile(path); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func (*File) Name() string { return "layout" }
+
+func (f *File) Mount(s tree.StateController) {
+	poll := f.Poll
+	if poll == 0 {
+		poll = DefaultPoll
+	}
+
+	f.mu.Lock()
+	f.stop = make(chan struct{})
+	stop := f.stop
+	f.mu.Unlock()
+
+	go func() {
+		t := time.NewTicker(poll)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				if f.reload() {
+					s.Update()
+				}
+			}
+		}
+	}()
+}
+
+func (f *File) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.stop != nil {
+		close(f.stop)
+		f.stop = nil
+	}
+}
+
+// ShouldUpdate reports true only if old is a different File;
+// changes to the file are picked up by polling, not by
+// the parent re-rendering.
+func (f *File) ShouldUpdate(old tree.Component) (bool, error) {
+	return old != tree.Component(f), nil
+}
+
+func (f *File) Render() ([]tree.Component, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(f.layout.Shape))
+
+	return []tree.Component{shaped{
+		key:       fmt.Sprintf("shape-%08x", h.Sum32()),
+		Component: f.layout.Component,
+	}}, nil
+}
+
+// reload reloads the file if it has changed,
+// reporting whether what the File renders did.
+func (f *File) reload() bool {
+	info, err := os.Stat(f.Path)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case err != nil:
+		changed := !f.missing || f.err.Error() != err.Error()
+		f.err, f.missing = err, true
+		return changed
+	case !f.missing && info.ModTime().Equal(f.modified) && info.Size() == f.size:
+		return false
+	}
+
+	f.modified, f.size, f.missing = info.ModTime(), info.Size(), false
+
+	debug.Log("reloading layout %s", f.Path)
+
+	l, err := f.Registry.ParseFile(f.Path)
+	if err != nil {
+		f.err = err
+		return true
+	}
+
+	f.layout, f.err = l, nil
+	return true
+}
+
+// A shaped Component renders a Layout. It is Keyed by the
+// Shape of the Layout, so that a Layout of a different Shape
+// replaces it rather than being reconciled against it.
+type shaped struct {
+	key string
+	tree.Component
+}
+
+func (s shaped) Name() string                            { return "layout " + s.key }
+func (shaped) Mount(tree.StateController)                {}
+func (shaped) Close()                                    {}
+func (shaped) ShouldUpdate(tree.Component) (bool, error) { return true, nil }
+func (s shaped) Render() ([]tree.Component, error)       { return []tree.Component{s.Component}, nil }
+func (s shaped) Key() string                             { return s.key }
@@ -0,0 +1,338 @@
+/*
+Package layout loads trees of Components from YAML or JSON documents, so that
+layouts can be changed without recompiling.
+
+A layout document describes a Component by the name it is registered under,
+its props, and its children:
+
+	type: column
+	props:
+	  gap: 1
+	children:
+	  - type: text
+	    props: {text: "hello"}
+	  - type: bar
+	    props: {progress: 0.5}
+
+Components are constructed by functions registered in a Registry, which are
+passed the props decoded into a type of their choosing, and their already
+constructed children:
+
+	r := layout.NewRegistry()
+	layout.Register(r, "text", func(p struct{ Text string }, _ []tree.Component) (tree.Component, error) {
+		return term.Text{Text: p.Text}, nil
+	})
+
+Since JSON is YAML, documents may also be written in JSON. Errors in
+documents are reported with the line and column they occur at.
+
+A File is a Component which renders the document in a file, and reloads it when
+the file changes, which it checks for every DefaultPoll unless told otherwise.
+*/
+package layout // import "zemn.me/reactive/layout"
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"zemn.me/reactive/tree"
+)
+
+// A builder constructs a Component from
+// its props and children.
+type builder func(props *yaml.Node, children []tree.Component) (tree.Component, error)
+
+// A Registry maps the names used in layout documents
+// to functions constructing Components.
+type Registry struct {
+	mu       sync.RWMutex
+	builders map[string]builder
+}
+
+// NewRegistry returns a new, empty Registry.
+func NewRegistry() *Registry { return &Registry{builders: make(map[string]builder)} }
+
+// Register registers build as the constructor of the Components
+// named name in layout documents. The props of each Component are
+// decoded into a P, as by yaml.Unmarshal, except that props P has
+// no field for are errors.
+//
+// Register panics if name is already registered.
+func Register[P any](r *Registry, name string, build func(props P, children []tree.Component) (tree.Component, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.builders[name]; ok {
+		panic(fmt.Sprintf("layout: %q registered twice", name))
+	}
+
+	r.builders[name] = func(node *yaml.Node, children []tree.Component) (tree.Component, error) {
+		var props P
+		if node != nil {
+			if err := decodeStrictly(node, &props); err != nil {
+				return nil, err
+			}
+		}
+
+		return build(props, children)
+	}
+}
+
+// decodeStrictly decodes node into v, as node.Decode does, but
+// rejecting fields v has no place for. Only a yaml.Decoder can do
+// that, so node is encoded and decoded again, and the lines of any
+// errors mapped back to those of node.
+func decodeStrictly(node *yaml.Node, v interface{}) error {
+	doc, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(doc))
+	dec.KnownFields(true)
+
+	err = dec.Decode(v)
+	te, ok := err.(*yaml.TypeError)
+	if !ok {
+		return err
+	}
+
+	var again yaml.Node
+	if yaml.Unmarshal(doc, &again) != nil || len(again.Content) != 1 {
+		return err
+	}
+
+	lines := make(map[int]int)
+	mapLines(again.Content[0], node, lines)
+
+	// these look like "line 3: cannot unmarshal ..."
+	for i, msg := range te.Errors {
+		var line int
+		if _, err := fmt.Sscanf(msg, "line %d:", &line); err != nil {
+			continue
+		}
+
+		if orig, ok := lines[line]; ok {
+			te.Errors[i] = fmt.Sprintf("line %d%s", orig, msg[strings.Index(msg, ":"):])
+		}
+	}
+
+	return te
+}
+
+// mapLines maps the lines of encoded, a copy of orig,
+// and of its contents, to the lines of orig.
+func mapLines(encoded, orig *yaml.Node, lines map[int]int) {
+	if _, ok := lines[encoded.Line]; !ok {
+		lines[encoded.Line] = orig.Line
+	}
+
+	for i := range encoded.Content {
+		if i < len(orig.Content) {
+			mapLines(encoded.Content[i], orig.Content[i], lines)
+		}
+	}
+}
+
+// Names returns the registered names, in order.
+func (r *Registry) Names() (names []string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.namesLocked()
+}
+
+// An Error is an error at a position in a layout document.
+// Column is zero if not known.
+type Error struct {
+	Line, Column int
+	Err          error
+}
+
+func (e Error) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Err)
+}
+
+// Errors are all the errors found in a layout document.
+type Errors []Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// A Layout is a tree of Components
+// constructed from a layout document.
+type Layout struct {
+	tree.Component
+
+	// Shape describes the names of the Components of the tree,
+	// and its structure. Layouts with the same Shape can be
+	// reconciled against one another.
+	Shape string
+}
+
+// Parse constructs the tree of Components described
+// by the layout document doc.
+func (r *Registry) Parse(doc []byte) (l Layout, err error) {
+	var root yaml.Node
+	if err = yaml.Unmarshal(doc, &root); err != nil {
+		return
+	}
+
+	if root.Kind != yaml.DocumentNode || len(root.Content) != 1 {
+		return l, Errors{{root.Line, root.Column, fmt.Errorf("empty layout document")}}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs Errors
+	var shape strings.Builder
+	l.Component = r.build(root.Content[0], &errs, &shape)
+	l.Shape = shape.String()
+
+	if len(errs) > 0 {
+		return Layout{}, errs
+	}
+
+	return
+}
+
+// ParseFile is Parse of the contents of the file at path.
+func (r *Registry) ParseFile(path string) (l Layout, err error) {
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	if l, err = r.Parse(doc); err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+
+	return
+}
+
+// build constructs the Component described by node, adding
+// any errors to errs, and describing its shape to shape.
+func (r *Registry) build(node *yaml.Node, errs *Errors, shape *strings.Builder) (c tree.Component) {
+	fail := func(n *yaml.Node, format string, args ...interface{}) {
+		*errs = append(*errs, Error{n.Line, n.Column, fmt.Errorf(format, args...)})
+	}
+
+	if node.Kind != yaml.MappingNode {
+		fail(node, "expected a Component, but found %s", node.ShortTag())
+		return
+	}
+
+	var name string
+	var typeNode, props *yaml.Node
+	var children []*yaml.Node
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		switch key.Value {
+		case "type":
+			typeNode = value
+			name = value.Value
+		case "props":
+			props = value
+		case "children":
+			if value.Kind != yaml.SequenceNode {
+				fail(value, "children should be a list, but found %s", value.ShortTag())
+				continue
+			}
+
+			children = value.Content
+		default:
+			fail(key, "unknown field %q; expected type, props or children", key.Value)
+		}
+	}
+
+	if typeNode == nil {
+		fail(node, "Component has no type")
+		return
+	}
+
+	b, ok := r.builders[name]
+	if !ok {
+		fail(typeNode, "no Component named %q is registered; registered: %s", name, strings.Join(r.namesLocked(), ", "))
+		return
+	}
+
+	fmt.Fprintf(shape, "%s(", name)
+
+	// a Component is not built from children which failed,
+	// but the rest are still built, to report their errors.
+	failed := len(*errs)
+
+	built := make([]tree.Component, len(children))
+	for i, child := range children {
+		if i > 0 {
+			shape.WriteString(",")
+		}
+
+		built[i] = r.build(child, errs, shape)
+	}
+
+	shape.WriteString(")")
+
+	if len(*errs) > failed {
+		return
+	}
+
+	c, err := b(props, built)
+	if err != nil {
+		if te, ok := err.(*yaml.TypeError); ok {
+			// without props, the error is in the builder's
+			// own decoding; report it at the Component.
+			at := props
+			if at == nil {
+				at = node
+			}
+
+			// these look like "line 3: cannot unmarshal ..."
+			for _, msg := range te.Errors {
+				e := Error{Line: at.Line, Column: at.Column}
+				if i := strings.Index(msg, ": "); i >= 0 {
+					if _, err := fmt.Sscanf(msg[:i], "line %d", &e.Line); err == nil {
+						e.Column, msg = 0, msg[i+2:]
+					}
+				}
+
+				e.Err = fmt.Errorf("%s: %s", name, msg)
+				*errs = append(*errs, e)
+			}
+
+			return
+		}
+
+		fail(node, "%s: %s", name, err)
+	}
+
+	return
+}
+
+func (r *Registry) namesLocked() (names []string) {
+	for name := range r.builders {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return
+}
@@ -0,0 +1,279 @@
+package layout_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	. "zemn.me/reactive/layout"
+	"zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+// a label records how it is mounted and closed.
+type label struct {
+	Text   string
+	Size   int
+	events *[]string
+}
+
+func (l *label) Name() string                            { return "label " + l.Text }
+func (l *label) Mount(tree.StateController)              { *l.events = append(*l.events, "mount "+l.Text) }
+func (l *label) Close()                                  { *l.events = append(*l.events, "close "+l.Text) }
+func (*label) ShouldUpdate(tree.Component) (bool, error) { return true, nil }
+func (*label) Render() ([]tree.Component, error)         { return nil, nil }
+
+// a box holds other Components.
+type box struct{ children []tree.Component }
+
+func (box) Name() string                              { return "box" }
+func (box) Mount(tree.StateController)                {}
+func (box) Close()                                    {}
+func (box) ShouldUpdate(tree.Component) (bool, error) { return true, nil }
+func (b box) Render() ([]tree.Component, error)       { return b.children, nil }
+
+var _ = Describe("Registry", func() {
+	var (
+		r      *Registry
+		events []string
+		boxes  int
+	)
+
+	BeforeEach(func() {
+		events, boxes = nil, 0
+		r = NewRegistry()
+
+		Register(r, "label", func(p struct {
+			Text string
+			Size int
+		}, _ []tree.Component) (tree.Component, error) {
+			if p.Size < 0 {
+				return nil, errors.New("size must not be negative")
+			}
+
+			return &label{Text: p.Text, Size: p.Size, events: &events}, nil
+		})
+
+		Register(r, "box", func(_ struct{}, children []tree.Component) (tree.Component, error) {
+			boxes++
+			return box{children}, nil
+		})
+	})
+
+	It("should panic if a name is registered twice", func() {
+		Expect(func() {
+			Register(r, "box", func(struct{}, []tree.Component) (tree.Component, error) { return nil, nil })
+		}).To(Panic())
+	})
+
+	It("should list the registered names", func() {
+		Expect(r.Names()).To(Equal([]string{"box", "label"}))
+	})
+
+	It("should build a tree from YAML", func() {
+		l, err := r.Parse([]byte(`
+type: box
+children:
+  - type: label
+    props: {text: hello, size: 2}
+  - type: label
+    props:
+      text: world
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l.Shape).To(Equal("box(label(),label())"))
+
+		b, ok := l.Component.(box)
+		Expect(ok).To(BeTrue())
+		Expect(b.children).To(HaveLen(2))
+		Expect(b.children[0].(*label).Text).To(Equal("hello"))
+		Expect(b.children[0].(*label).Size).To(Equal(2))
+		Expect(b.children[1].(*label).Text).To(Equal("world"))
+	})
+
+	It("should build a tree from JSON", func() {
+		l, err := r.Parse([]byte(`{
+	"type": "box",
+	"children": [{"type": "label", "props": {"text": "hi"}}]
+}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(l.Shape).To(Equal("box(label())"))
+		Expect(l.Component.(box).children[0].(*label).Text).To(Equal("hi"))
+	})
+
+	It("should report every error with its line", func() {
+		_, err := r.Parse([]byte(`type: box
+children:
+  - type: lable
+  - type: label
+    colour: red
+  - type: label
+    props:
+      size: big
+  - type: label
+    props: {size: -1}
+`))
+
+		var errs Errors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs).To(HaveLen(4))
+
+		Expect(errs[0].Line).To(Equal(3))
+		Expect(errs[0].Error()).To(ContainSubstring(`no Component named "lable"`))
+		Expect(errs[0].Error()).To(ContainSubstring("registered: box, label"))
+
+		Expect(errs[1].Line).To(Equal(5))
+		Expect(errs[1].Error()).To(ContainSubstring(`unknown field "colour"`))
+
+		Expect(errs[2].Line).To(Equal(8))
+		Expect(errs[2].Error()).To(ContainSubstring("cannot unmarshal"))
+
+		Expect(errs[3].Line).To(Equal(9))
+		Expect(errs[3].Error()).To(ContainSubstring("size must not be negative"))
+	})
+
+	It("should report unknown props at their line", func() {
+		_, err := r.Parse([]byte(`type: box
+children:
+  - type: label
+    props:
+      text: hi
+      colour: red
+`))
+
+		var errs Errors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Line).To(Equal(6))
+		Expect(errs[0].Error()).To(ContainSubstring("field colour not found"))
+	})
+
+	It("should not build a Component whose children failed", func() {
+		_, err := r.Parse([]byte("type: box\nchildren: [{type: box}, {type: lable}]\n"))
+		Expect(err).To(HaveOccurred())
+		Expect(boxes).To(Equal(1))
+	})
+
+	It("should report type errors of Components without props at the Component", func() {
+		Register(r, "picky", func(_ struct{}, _ []tree.Component) (tree.Component, error) {
+			return nil, &yaml.TypeError{Errors: []string{"picky needs props"}}
+		})
+
+		_, err := r.Parse([]byte("type: box\nchildren:\n  - type: picky\n"))
+
+		var errs Errors
+		Expect(errors.As(err, &errs)).To(BeTrue())
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Line).To(Equal(3))
+		Expect(errs[0].Error()).To(ContainSubstring("picky needs props"))
+	})
+
+	It("should reject an empty document", func() {
+		_, err := r.Parse(nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("File", func() {
+		var (
+			dir  string
+			path string
+			f    *File
+			s    *tree.Scheduler
+			rec  treetest.Recorder
+		)
+
+		write := func(doc string) {
+			Expect(os.WriteFile(path, []byte(doc), 0o644)).To(Succeed())
+
+			// make sure the change is seen even if the
+			// filesystem's clock is coarse
+			later := time.Now().Add(time.Duration(len(doc)) * time.Second)
+			Expect(os.Chtimes(path, later, later)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			rec.Clear()
+			var err error
+			dir, err = os.MkdirTemp("", "layout")
+			Expect(err).ToNot(HaveOccurred())
+
+			path = filepath.Join(dir, "layout.yaml")
+			write("type: box\nchildren: [{type: label, props: {text: a}}]\n")
+
+			f, err = NewFile(r, path)
+			Expect(err).ToNot(HaveOccurred())
+			f.Poll = time.Millisecond
+
+			s = tree.NewScheduler()
+			s.NewNode(f, &rec)
+			s.Flush()
+
+			Expect(events).To(Equal([]string{"mount a"}))
+		})
+
+		AfterEach(func() {
+			f.Close()
+			os.RemoveAll(dir)
+		})
+
+		settle := func() []string {
+			s.Flush()
+			return events
+		}
+
+		It("should reconcile a reloaded layout of the same shape", func() {
+			write("type: box\nchildren: [{type: label, props: {text: b}}]\n")
+
+			Eventually(func() []tree.Component { s.Flush(); return rec.Components }).
+				Should(ContainElement(&label{Text: "b", events: &events}))
+			Expect(events).To(Equal([]string{"mount a"}))
+		})
+
+		It("should remount a reloaded layout of a different shape", func() {
+			write("type: label\nprops: {text: c}\n")
+
+			Eventually(settle).Should(Equal([]string{"mount a", "close a", "mount c"}))
+		})
+
+		It("should report errors, keeping the old layout", func() {
+			write("type: nothing\n")
+
+			Eventually(func() []treetest.RecordedError { s.Flush(); return rec.Errors }).
+				ShouldNot(BeEmpty())
+			Expect(rec.Errors[0].Err.Error()).To(ContainSubstring(`no Component named "nothing"`))
+			Expect(events).To(Equal([]string{"mount a"}))
+		})
+
+		It("should not confuse two Files of the same path", func() {
+			f.Close()
+			g, err := NewFile(r, path)
+			Expect(err).ToNot(HaveOccurred())
+			g.Poll = time.Millisecond
+			defer g.Close()
+
+			events = nil
+			s.NewNode(box{[]tree.Component{f, g}}, &rec)
+			s.Flush()
+			Expect(events).To(Equal([]string{"mount a", "mount a"}))
+
+			write("type: label\nprops: {text: c}\n")
+
+			Eventually(func() int { s.Flush(); return len(events) }).Should(Equal(6))
+			Expect(events).To(ConsistOf("mount a", "mount a", "close a", "close a", "mount c", "mount c"))
+			Expect(rec.Errors).To(BeEmpty())
+		})
+
+		It("should report a missing file once", func() {
+			Expect(os.Remove(path)).To(Succeed())
+
+			errs := func() int { s.Flush(); return len(rec.Errors) }
+			Eventually(errs).Should(Equal(1))
+			Consistently(errs, 50*time.Millisecond).Should(Equal(1))
+		})
+	})
+})
@@ -2,6 +2,33 @@
 //components.
 //
 // For more in-depth information on the design, see ./tree.
+//
+// Signals
+//
+// A Signal is a value which remembers who reads it. Reading a Signal
+// with Get while rendering a Component, computing a Computed or running
+// an Effect makes the reader depend on the Signal, and when the Signal is
+// Set, everything that depends on it is updated:
+//
+//	count := reactive.NewSignal(0)
+//	double := reactive.NewComputed(func() int { return count.Get() * 2 })
+//
+//	func (c Counter) Render() ([]tree.Component, error) {
+//		return []tree.Component{term.Text{Text: fmt.Sprint(double.Get())}}, nil
+//	}
+//
+//	count.Set(count.Get() + 1) // re-renders Counter
+//
+// Components are updated through their StateController, so they need no
+// Update() plumbing of their own.
+//
+// Dependencies are tracked separately for each render, Computed and
+// Effect being evaluated, and on each goroutine, so Signals may be Set
+// from any goroutine, such as a timer's, and trees rendering at once
+// on different goroutines do not see each other's reads. Reading a
+// Signal on a goroutine which is not evaluating anything depends on
+// nothing. Set runs the Effects depending on the Signal on the
+// goroutine that calls it.
 package reactive
 
 import "zemn.me/reactive/tree"
@@ -0,0 +1,364 @@
+package reactive
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"zemn.me/reactive/tree"
+)
+
+func init() { tree.RegisterTracker(renderTracker{}) }
+
+// an observer is told when something
+// it depends on has changed.
+type observer interface{ invalidate() }
+
+// a source is something that can be depended on.
+type source interface {
+	subscribe(o observer)
+	unsubscribe(o observer)
+}
+
+type observers struct {
+	mu  sync.Mutex
+	set map[observer]struct{}
+}
+
+func (o *observers) subscribe(ob observer) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.set == nil {
+		o.set = make(map[observer]struct{})
+	}
+
+	o.set[ob] = struct{}{}
+}
+
+func (o *observers) unsubscribe(ob observer) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.set, ob)
+}
+
+func (o *observers) notify() {
+	o.mu.Lock()
+	obs := make([]observer, 0, len(o.set))
+	for ob := range o.set {
+		obs = append(obs, ob)
+	}
+	o.mu.Unlock()
+
+	for _, ob := range obs {
+		ob.invalidate()
+	}
+}
+
+// a collector collects the sources
+// read during an evaluation.
+type collector struct {
+	sources []source
+	seen    map[source]bool
+}
+
+// tracking holds the stack of evaluations in progress on each
+// goroutine, so that evaluations on different goroutines, such as
+// the renders of two Schedulers, do not see each other's reads.
+var tracking struct {
+	// the number of evaluations in progress on every
+	// goroutine, so that reads outside of any need not
+	// find out which goroutine they are on.
+	active int32
+
+	sync.Mutex
+	stacks map[uint64][]*collector
+}
+
+// track calls f, returning every source
+// read during f on the calling goroutine.
+func track(f func()) []source {
+	c := &collector{seen: make(map[source]bool)}
+	g := goroutine()
+
+	atomic.AddInt32(&tracking.active, 1)
+	tracking.Lock()
+	if tracking.stacks == nil {
+		tracking.stacks = make(map[uint64][]*collector)
+	}
+	tracking.stacks[g] = append(tracking.stacks[g], c)
+	tracking.Unlock()
+
+	defer func() {
+		tracking.Lock()
+		if stack := tracking.stacks[g]; len(stack) > 1 {
+			tracking.stacks[g] = stack[:len(stack)-1]
+		} else {
+			delete(tracking.stacks, g)
+		}
+		tracking.Unlock()
+		atomic.AddInt32(&tracking.active, -1)
+	}()
+
+	f()
+
+	return c.sources
+}
+
+// read records s as having been read by the current
+// evaluation on the calling goroutine, if there is one.
+func read(s source) {
+	if atomic.LoadInt32(&tracking.active) == 0 {
+		return
+	}
+
+	g := goroutine()
+
+	tracking.Lock()
+	defer tracking.Unlock()
+
+	stack := tracking.stacks[g]
+	if len(stack) == 0 {
+		return
+	}
+
+	c := stack[len(stack)-1]
+	if c.seen[s] {
+		return
+	}
+
+	c.seen[s] = true
+	c.sources = append(c.sources, s)
+}
+
+// goroutine returns the ID of the calling goroutine,
+// which the runtime only exposes in stack traces.
+func goroutine() (id uint64) {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		id, _ = strconv.ParseUint(string(b[:i]), 10, 64)
+	}
+
+	return
+}
+
+func subscribeAll(o observer, sources []source) {
+	for _, s := range sources {
+		s.subscribe(o)
+	}
+}
+
+func unsubscribeAll(o observer, sources []source) {
+	for _, s := range sources {
+		s.unsubscribe(o)
+	}
+}
+
+// unchanged reports whether a and b are the same value
+// of a comparable type. Values which cannot be compared
+// are never the same.
+func unchanged[T any](a, b T) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+
+	return any(a) == any(b)
+}
+
+// A Signal holds a value of type T which can be
+// depended upon.
+type Signal[T any] struct {
+	observers
+
+	mu    sync.Mutex
+	value T
+}
+
+// NewSignal returns a new Signal holding v.
+func NewSignal[T any](v T) *Signal[T] { return &Signal[T]{value: v} }
+
+// Get returns the value of the Signal, and
+// depends on it if evaluating.
+func (s *Signal[T]) Get() T {
+	read(s)
+
+	return s.Peek()
+}
+
+// Peek returns the value of the Signal
+// without depending on it.
+func (s *Signal[T]) Peek() T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.value
+}
+
+// Set sets the value of the Signal, updating
+// everything that depends on it, unless v is
+// comparable and equal to the current value.
+func (s *Signal[T]) Set(v T) {
+	s.mu.Lock()
+	if unchanged(s.value, v) {
+		s.mu.Unlock()
+		return
+	}
+	s.value = v
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+// Modify sets the value of the Signal to f
+// of its current value.
+func (s *Signal[T]) Modify(f func(T) T) { s.Set(f(s.Peek())) }
+
+// A Computed is a value computed from Signals and other
+// Computed values. It is recomputed when next read after
+// something it depends on changes.
+type Computed[T any] struct {
+	observers
+
+	f func() T
+
+	mu      sync.Mutex
+	value   T
+	dirty   bool
+	sources []source
+}
+
+// NewComputed returns a new Computed whose
+// value is the result of f.
+func NewComputed[T any](f func() T) *Computed[T] {
+	return &Computed[T]{f: f, dirty: true}
+}
+
+// Get returns the value of the Computed, computing
+// it if needed, and depends on it if evaluating.
+func (c *Computed[T]) Get() T {
+	read(c)
+
+	c.mu.Lock()
+	if !c.dirty {
+		defer c.mu.Unlock()
+		return c.value
+	}
+	old := c.sources
+	c.mu.Unlock()
+
+	unsubscribeAll(c, old)
+
+	var v T
+	sources := track(func() { v = c.f() })
+
+	c.mu.Lock()
+	c.value, c.sources, c.dirty = v, sources, false
+	c.mu.Unlock()
+
+	subscribeAll(c, sources)
+
+	return v
+}
+
+func (c *Computed[T]) invalidate() {
+	c.mu.Lock()
+	if c.dirty {
+		c.mu.Unlock()
+		return
+	}
+	c.dirty = true
+	c.mu.Unlock()
+
+	c.notify()
+}
+
+// An Effect is a function which is run again each
+// time something it depends on changes.
+type Effect struct {
+	f func()
+
+	mu      sync.Mutex
+	running bool
+	stopped bool
+	sources []source
+}
+
+// NewEffect runs f, and runs it again each time something
+// it read changes, until the Effect is stopped.
+//
+// Changes made by f to what it depends on do not
+// cause it to run again.
+func NewEffect(f func()) (e *Effect) {
+	e = &Effect{f: f}
+	e.run()
+	return
+}
+
+func (e *Effect) run() {
+	e.mu.Lock()
+	if e.running || e.stopped {
+		e.mu.Unlock()
+		return
+	}
+	e.running = true
+	old := e.sources
+	e.mu.Unlock()
+
+	unsubscribeAll(e, old)
+
+	sources := track(e.f)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.running = false
+	e.sources = sources
+
+	if !e.stopped {
+		subscribeAll(e, sources)
+	}
+}
+
+func (e *Effect) invalidate() { e.run() }
+
+// Stop stops the Effect from running again.
+func (e *Effect) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stopped = true
+	unsubscribeAll(e, e.sources)
+}
+
+// renderTracker subscribes Components
+// to what they read while rendering.
+type renderTracker struct{}
+
+func (renderTracker) Render(c tree.Component, render func() ([]tree.Component, error)) (children []tree.Component, dep tree.Dependency, err error) {
+	sources := track(func() { children, err = render() })
+	if len(sources) > 0 {
+		dep = dependency(sources)
+	}
+
+	return
+}
+
+// a dependency is the sources read
+// while rendering a Component.
+type dependency []source
+
+type watcher struct{ tree.StateController }
+
+func (w *watcher) invalidate() { w.Update() }
+
+func (d dependency) Watch(s tree.StateController) (cancel func()) {
+	w := &watcher{s}
+	subscribeAll(w, d)
+	return func() { unsubscribeAll(w, d) }
+}
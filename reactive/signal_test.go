@@ -0,0 +1,179 @@
+package reactive_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive"
+	"zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+// counter is a StaticComponent which
+// reads a Signal when it renders.
+type counter struct {
+	*treetest.StaticComponent
+	count *Signal[int]
+	seen  *[]int
+}
+
+func (c counter) Render() ([]tree.Component, error) {
+	*c.seen = append(*c.seen, c.count.Get())
+	return c.StaticComponent.Render()
+}
+
+var _ = Describe("Signal", func() {
+	It("should hold a value", func() {
+		s := NewSignal("a")
+		Expect(s.Get()).To(Equal("a"))
+		s.Set("b")
+		Expect(s.Get()).To(Equal("b"))
+	})
+
+	It("should be modifiable", func() {
+		s := NewSignal(1)
+		s.Modify(func(v int) int { return v + 1 })
+		Expect(s.Get()).To(Equal(2))
+	})
+
+	When("read while rendering a Component", func() {
+		var (
+			count *Signal[int]
+			seen  []int
+			rec   treetest.Recorder
+			c     counter
+		)
+
+		BeforeEach(func() {
+			seen = nil
+			rec.Clear()
+			count = NewSignal(0)
+			c = counter{&treetest.StaticComponent{Id: "counter"}, count, &seen}
+
+			tree.NewNode(c, &rec)
+		})
+
+		It("should re-render the Component when set", func() {
+			count.Set(1)
+			count.Set(2)
+			Expect(seen).To(Equal([]int{0, 1, 2}))
+		})
+
+		It("should not re-render the Component when set to the same value", func() {
+			count.Set(0)
+			Expect(seen).To(Equal([]int{0}))
+		})
+
+		It("should stop re-rendering the Component once it is closed", func() {
+			parent := &treetest.StaticComponent{Id: "parent", Children: []tree.Component{c}}
+			tree.NewNode(parent, &rec)
+			Expect(seen).To(Equal([]int{0, 0}))
+
+			parent.Children = []tree.Component{nil}
+			parent.ForceUpdate()
+
+			count.Set(1)
+			Expect(seen).To(Equal([]int{0, 0, 1}))
+		})
+	})
+})
+
+var _ = Describe("Computed", func() {
+	var (
+		a, b    *Signal[int]
+		useA    *Signal[bool]
+		runs    int
+		sum     *Computed[int]
+		effects []int
+	)
+
+	BeforeEach(func() {
+		runs = 0
+		effects = nil
+		a, b, useA = NewSignal(1), NewSignal(10), NewSignal(true)
+		sum = NewComputed(func() int {
+			runs++
+			if useA.Get() {
+				return a.Get() + b.Get()
+			}
+			return b.Get()
+		})
+	})
+
+	It("should compute lazily, and only once", func() {
+		Expect(runs).To(Equal(0))
+		Expect(sum.Get()).To(Equal(11))
+		Expect(sum.Get()).To(Equal(11))
+		Expect(runs).To(Equal(1))
+	})
+
+	It("should recompute when a dependency changes", func() {
+		sum.Get()
+		a.Set(2)
+		Expect(sum.Get()).To(Equal(12))
+		Expect(runs).To(Equal(2))
+	})
+
+	It("should only depend on what it last read", func() {
+		sum.Get()
+		useA.Set(false)
+		Expect(sum.Get()).To(Equal(10))
+
+		a.Set(100)
+		Expect(sum.Get()).To(Equal(10))
+		Expect(runs).To(Equal(2))
+	})
+
+	It("should not depend on what other goroutines read meanwhile", func(done Done) {
+		defer close(done)
+
+		other := NewSignal(0)
+		reading, read := make(chan bool), make(chan bool)
+		go func() {
+			<-reading
+			other.Get()
+			close(read)
+		}()
+
+		runs := 0
+		e := NewEffect(func() {
+			if runs++; runs == 1 {
+				close(reading)
+				<-read
+			}
+
+			a.Get()
+		})
+		defer e.Stop()
+
+		other.Set(1)
+		Expect(runs).To(Equal(1))
+
+		a.Set(2)
+		Expect(runs).To(Equal(2))
+	})
+
+	Describe("Effect", func() {
+		var e *Effect
+
+		BeforeEach(func() {
+			e = NewEffect(func() { effects = append(effects, sum.Get()) })
+		})
+
+		It("should run immediately", func() {
+			Expect(effects).To(Equal([]int{11}))
+		})
+
+		It("should run again when something it depends on changes", func() {
+			a.Set(2)
+			b.Set(20)
+			Expect(effects).To(Equal([]int{11, 12, 22}))
+		})
+
+		It("should not run once stopped", func() {
+			e.Stop()
+			a.Set(2)
+			Expect(effects).To(Equal([]int{11}))
+		})
+	})
+})
@@ -0,0 +1,302 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/event"
+)
+
+// An Event is a record of a call made to a Mapper.
+type Event struct {
+	Kind event.Kind
+
+	// the Name() and type of the Component
+	Component, Type string
+
+	// the Path of the Component, if
+	// the tree reported it
+	Path string `json:",omitempty"`
+
+	// for an event.Error, the error
+	Error string `json:",omitempty"`
+}
+
+// A Frame is every Event of a single committed update.
+type Frame []Event
+
+// A Transition is an action dispatched to a Store, the model
+// which resulted from it, and the Frames committed while the
+// model was showing.
+type Transition[M, A any] struct {
+	Action A
+	Model  M
+	Frames []Frame
+}
+
+// A History records the actions dispatched to a Store and the Frames
+// committed to a Mapper, and can step back and forth through them,
+// replacing the model of the Store as it goes.
+//
+// A History is attached to a Store by passing its Middleware method
+// to New, and to a tree by wrapping the Mapper with its Mapper method:
+//
+//	h := store.NewHistory[Model, Action]()
+//	s := store.New(Model{}, reduce, h.Middleware)
+//	tree.NewNode(root, h.Mapper(mapper))
+//
+// Dispatching an action while stepped back discards the Transitions
+// after the current one.
+type History[M, A any] struct {
+	mu     sync.Mutex
+	store  *Store[M, A]
+	record record[M, A]
+
+	// the number of Transitions applied
+	// to the model of the Store
+	cursor int
+
+	// the Events of the uncommitted Frame
+	events []Event
+
+	// seeking is set by Seek until the next action
+	// is dispatched; Frames committed meanwhile replay
+	// the History, and are not recorded.
+	seeking bool
+}
+
+// record is what is saved and loaded by a History.
+type record[M, A any] struct {
+	Initial       M
+	InitialFrames []Frame
+	Transitions   []Transition[M, A]
+}
+
+// NewHistory returns a new, empty History.
+func NewHistory[M, A any]() *History[M, A] { return new(History[M, A]) }
+
+// Middleware records each action reduced by s. It may
+// only be used with one Store.
+func (h *History[M, A]) Middleware(s *Store[M, A], next func(A)) func(A) {
+	h.mu.Lock()
+	h.store = s
+	h.record.Initial = s.Model()
+	h.mu.Unlock()
+
+	s.mu.Lock()
+	s.reduced = append(s.reduced, h.transition)
+	s.mu.Unlock()
+
+	return next
+}
+
+func (h *History[M, A]) transition(action A, m M) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.record.Transitions = append(
+		h.record.Transitions[:h.cursor],
+		Transition[M, A]{Action: action, Model: m},
+	)
+	h.cursor = len(h.record.Transitions)
+	h.seeking = false
+}
+
+// Mapper returns a Mapper which records every Frame
+// committed to m. It is a tree.PathMapper, passing
+// Paths on to m if m is one too.
+func (h *History[M, A]) Mapper(m tree.Mapper) tree.Committer {
+	return historyMapper[M, A]{h, m}
+}
+
+var _ tree.PathMapper = historyMapper[int, int]{}
+
+type historyMapper[M, A any] struct {
+	*History[M, A]
+	m tree.Mapper
+}
+
+func (hm historyMapper[M, A]) Map(c tree.Component)   { hm.MapPath(nil, c) }
+func (hm historyMapper[M, A]) UnMap(c tree.Component) { hm.UnMapPath(nil, c) }
+func (hm historyMapper[M, A]) Error(c tree.Component, err error) {
+	hm.ErrorPath(nil, c, err)
+}
+
+func (hm historyMapper[M, A]) MapPath(p tree.Path, c tree.Component) {
+	hm.observe(event.Map, p, c, nil)
+
+	if pm, ok := hm.m.(tree.PathMapper); ok && p != nil {
+		pm.MapPath(p, c)
+	} else {
+		hm.m.Map(c)
+	}
+}
+
+func (hm historyMapper[M, A]) UnMapPath(p tree.Path, c tree.Component) {
+	hm.observe(event.UnMap, p, c, nil)
+
+	if pm, ok := hm.m.(tree.PathMapper); ok && p != nil {
+		pm.UnMapPath(p, c)
+	} else {
+		hm.m.UnMap(c)
+	}
+}
+
+func (hm historyMapper[M, A]) ErrorPath(p tree.Path, c tree.Component, err error) {
+	hm.observe(event.Error, p, c, err)
+
+	if pm, ok := hm.m.(tree.PathMapper); ok && p != nil {
+		pm.ErrorPath(p, c, err)
+	} else {
+		hm.m.Error(c, err)
+	}
+}
+
+func (hm historyMapper[M, A]) Commit() {
+	hm.commit()
+
+	if c, ok := hm.m.(tree.Committer); ok {
+		c.Commit()
+	}
+}
+
+func (h *History[M, A]) observe(kind event.Kind, p tree.Path, c tree.Component, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	e := Event{Kind: kind, Component: c.Name(), Type: fmt.Sprintf("%T", c), Path: p.String()}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	h.events = append(h.events, e)
+}
+
+// commit adds the uncommitted Frame to
+// the current Transition.
+func (h *History[M, A]) commit() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	frame := Frame(h.events)
+	h.events = nil
+
+	if h.seeking {
+		return
+	}
+
+	if h.cursor == 0 {
+		h.record.InitialFrames = append(h.record.InitialFrames, frame)
+		return
+	}
+
+	t := &h.record.Transitions[h.cursor-1]
+	t.Frames = append(t.Frames, frame)
+}
+
+// Len returns the number of recorded Transitions.
+func (h *History[M, A]) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.record.Transitions)
+}
+
+// Cursor returns the number of recorded Transitions applied
+// to the model of the Store. It is equal to Len() unless the
+// History has been stepped back.
+func (h *History[M, A]) Cursor() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.cursor
+}
+
+// Transitions returns the recorded Transitions.
+func (h *History[M, A]) Transitions() []Transition[M, A] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]Transition[M, A](nil), h.record.Transitions...)
+}
+
+// InitialFrames returns the Frames committed before
+// any Transition.
+func (h *History[M, A]) InitialFrames() []Frame {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]Frame(nil), h.record.InitialFrames...)
+}
+
+// Seek replaces the model of the Store with the model
+// after the first i Transitions, or the initial model if
+// i is 0. Frames committed after a Seek are not recorded
+// until the next action is dispatched.
+func (h *History[M, A]) Seek(i int) (err error) {
+	h.mu.Lock()
+
+	if h.store == nil {
+		h.mu.Unlock()
+		return fmt.Errorf("History is not attached to a Store")
+	}
+
+	if i < 0 || i > len(h.record.Transitions) {
+		h.mu.Unlock()
+		return fmt.Errorf(
+			"cannot seek to %d; there are %d Transitions",
+			i, len(h.record.Transitions),
+		)
+	}
+
+	m := h.record.Initial
+	if i > 0 {
+		m = h.record.Transitions[i-1].Model
+	}
+
+	h.cursor = i
+	h.seeking = true
+	s := h.store
+	h.mu.Unlock()
+
+	s.mu.Lock()
+	s.replace(m)
+	return
+}
+
+// Back steps back one Transition, reporting
+// whether there was one to step back to.
+func (h *History[M, A]) Back() bool { return h.Seek(h.Cursor()-1) == nil }
+
+// Forward steps forward one Transition, reporting
+// whether there was one to step forward to.
+func (h *History[M, A]) Forward() bool { return h.Seek(h.Cursor()+1) == nil }
+
+// Save writes the History to w as JSON.
+// M and A must be JSON serializable.
+func (h *History[M, A]) Save(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return json.NewEncoder(w).Encode(h.record)
+}
+
+// Load replaces the History with one written by Save, and
+// seeks to the end of it. The History must already be attached
+// to a Store.
+func (h *History[M, A]) Load(r io.Reader) (err error) {
+	var rec record[M, A]
+	if err = json.NewDecoder(r).Decode(&rec); err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	h.record = rec
+	h.events = nil
+	h.mu.Unlock()
+
+	return h.Seek(len(rec.Transitions))
+}
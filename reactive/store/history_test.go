@@ -0,0 +1,140 @@
+package store_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive/store"
+	"zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/event"
+	"zemn.me/reactive/tree/treetest"
+)
+
+var _ = Describe("History", func() {
+	var (
+		h     *store.History[model, action]
+		s     *store.Store[model, action]
+		count *store.Selection[int]
+		seen  []int
+		rec   treetest.Recorder
+	)
+
+	BeforeEach(func() {
+		seen = nil
+		rec.Clear()
+		h = store.NewHistory[model, action]()
+		s = store.New(model{}, reduce, h.Middleware)
+		count = store.Select(s, func(m model) int { return m.Count })
+
+		tree.NewNode(
+			counter{&treetest.StaticComponent{Id: "counter"}, count, &seen},
+			h.Mapper(&rec),
+		)
+
+		s.Dispatch(action{Add: 1})
+		s.Dispatch(action{Add: 2})
+	})
+
+	AfterEach(func() { count.Close() })
+
+	It("should record each action and the resulting model", func() {
+		Expect(h.Transitions()).To(HaveLen(2))
+		Expect(h.Transitions()[0].Action).To(Equal(action{Add: 1}))
+		Expect(h.Transitions()[1].Model).To(Equal(model{Count: 3}))
+		Expect(h.Cursor()).To(Equal(2))
+	})
+
+	It("should record the frames committed after each action", func() {
+		Expect(h.InitialFrames()).To(HaveLen(1))
+		for _, t := range h.Transitions() {
+			Expect(t.Frames).To(HaveLen(1))
+			Expect(t.Frames[0]).To(HaveLen(1))
+			Expect(t.Frames[0][0].Kind).To(Equal(event.Map))
+			Expect(t.Frames[0][0].Component).To(ContainSubstring("counter"))
+			Expect(t.Frames[0][0].Path).To(Equal("root"))
+		}
+	})
+
+	It("should still pass events to the wrapped Mapper", func() {
+		Expect(rec.Components).To(HaveLen(3))
+		Expect(rec.Paths(event.Map)).To(Equal([]string{"root", "root", "root"}))
+	})
+
+	When("stepped back", func() {
+		BeforeEach(func() {
+			Expect(h.Back()).To(BeTrue())
+		})
+
+		It("should restore the previous model", func() {
+			Expect(s.Model()).To(Equal(model{Count: 1}))
+			Expect(seen).To(Equal([]int{0, 1, 3, 1}))
+		})
+
+		It("should be able to step forward again", func() {
+			Expect(h.Forward()).To(BeTrue())
+			Expect(s.Model()).To(Equal(model{Count: 3}))
+			Expect(h.Forward()).To(BeFalse())
+		})
+
+		It("should not step back past the initial model", func() {
+			Expect(h.Back()).To(BeTrue())
+			Expect(s.Model()).To(Equal(model{}))
+			Expect(h.Back()).To(BeFalse())
+		})
+
+		It("should discard the future when an action is dispatched", func() {
+			s.Dispatch(action{Add: 10})
+			Expect(h.Len()).To(Equal(2))
+			Expect(s.Model()).To(Equal(model{Count: 11}))
+		})
+
+		It("should not record the frames it replays", func() {
+			Expect(h.Forward()).To(BeTrue())
+			Expect(h.Back()).To(BeTrue())
+			Expect(h.Back()).To(BeTrue())
+			Expect(h.Forward()).To(BeTrue())
+
+			Expect(h.InitialFrames()).To(HaveLen(1))
+			for _, t := range h.Transitions() {
+				Expect(t.Frames).To(HaveLen(1))
+			}
+		})
+
+		It("should record frames again once an action is dispatched", func() {
+			s.Dispatch(action{Add: 10})
+			Expect(h.Transitions()[1].Frames).To(HaveLen(1))
+		})
+	})
+
+	When("saved and loaded", func() {
+		var (
+			h2 *store.History[model, action]
+			s2 *store.Store[model, action]
+		)
+
+		BeforeEach(func() {
+			var b bytes.Buffer
+			Expect(h.Save(&b)).To(Succeed())
+
+			h2 = store.NewHistory[model, action]()
+			s2 = store.New(model{}, reduce, h2.Middleware)
+			Expect(h2.Load(&b)).To(Succeed())
+		})
+
+		It("should reproduce the History", func() {
+			Expect(h2.Transitions()).To(Equal(h.Transitions()))
+			Expect(h2.InitialFrames()).To(Equal(h.InitialFrames()))
+		})
+
+		It("should replace the model", func() {
+			Expect(s2.Model()).To(Equal(model{Count: 3}))
+		})
+
+		It("should be able to step through it", func() {
+			Expect(h2.Seek(1)).To(Succeed())
+			Expect(s2.Model()).To(Equal(model{Count: 1}))
+		})
+	})
+})
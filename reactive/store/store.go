@@ -0,0 +1,245 @@
+/*
+Package store implements Elm or Redux-like state management for trees of
+Components.
+
+A Store holds a model of type M, which is never modified, only replaced. The
+model is replaced by dispatching an action of type A, which is passed with the
+current model to a Reducer to produce the next model:
+
+	type Model struct{ Count int }
+	type Action int
+
+	s := store.New(Model{}, func(m Model, a Action) Model {
+		m.Count += int(a)
+		return m
+	}, store.Log[Model, Action])
+
+	s.Dispatch(1)
+
+Components do not usually read the whole model. Instead, a Selection
+picks out the part of the model a Component cares about. Reading a
+Selection while rendering subscribes the Component to it, as with a
+zemn.me/reactive Signal, and the Component is re-rendered only when
+the selected value changes:
+
+	count := store.Select(s, func(m Model) int { return m.Count })
+
+	func (c Counter) Render() ([]tree.Component, error) {
+		return []tree.Component{term.Text{Text: fmt.Sprint(count.Get())}}, nil
+	}
+
+Actions may be dispatched from any goroutine. Selections are updated, and
+so the Effects depending on them run, on the goroutine dispatching the
+action; Components are re-rendered as their trees schedule it. An action
+may be dispatched while a Selection is being updated, for example by an
+Effect.
+
+A History records every action, the model it produced and the frames
+committed to a Mapper, so that a tree can be stepped back and forth through
+its states, or a recording saved and loaded to reproduce a bug offline.
+*/
+package store // import "zemn.me/reactive/store"
+
+import (
+	"sync"
+
+	"zemn.me/debug"
+	"zemn.me/reactive"
+)
+
+// A Reducer returns the model which results from
+// applying action to model. It should not modify model.
+type Reducer[M, A any] func(model M, action A) M
+
+// A Middleware wraps the dispatching of actions to a Store.
+// It is passed next, which dispatches an action to the rest of
+// the chain, and returns the function to call in its place.
+type Middleware[M, A any] func(s *Store[M, A], next func(A)) func(A)
+
+// Log is a Middleware which logs each action, and the model
+// resulting from it, via zemn.me/debug.
+func Log[M, A any](s *Store[M, A], next func(A)) func(A) {
+	return func(action A) {
+		debug.Log("store: dispatching %+v", action)
+		next(action)
+		debug.Log("store: model now %+v", s.Model())
+	}
+}
+
+// A Store holds a model of type M, which is replaced
+// by dispatching actions of type A.
+type Store[M, A any] struct {
+	reduce   Reducer[M, A]
+	dispatch func(A)
+
+	mu         sync.Mutex
+	model      M
+	version    uint64
+	selections map[selection[M]]struct{}
+
+	// called with each action and the model
+	// reduced from it, while mu is held.
+	reduced []func(action A, model M)
+}
+
+// A selection is told about each new model.
+type selection[M any] interface {
+	update(version uint64, model M)
+}
+
+// New returns a new Store holding the model initial, reducing actions
+// with reduce. Dispatched actions pass through each Middleware in turn
+// before reaching reduce.
+func New[M, A any](initial M, reduce Reducer[M, A], middleware ...Middleware[M, A]) (s *Store[M, A]) {
+	s = &Store[M, A]{
+		reduce:     reduce,
+		model:      initial,
+		selections: make(map[selection[M]]struct{}),
+	}
+
+	s.dispatch = s.apply
+	for i := len(middleware) - 1; i >= 0; i-- {
+		s.dispatch = middleware[i](s, s.dispatch)
+	}
+
+	return
+}
+
+// Model returns the current model.
+func (s *Store[M, A]) Model() M {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.model
+}
+
+// Dispatch dispatches action to the Store.
+func (s *Store[M, A]) Dispatch(action A) { s.dispatch(action) }
+
+// apply is the end of the Middleware chain,
+// reducing action into the model.
+func (s *Store[M, A]) apply(action A) {
+	s.mu.Lock()
+	m := s.reduce(s.model, action)
+
+	for _, f := range s.reduced {
+		f(action, m)
+	}
+
+	s.replace(m)
+}
+
+// replace replaces the model with m, updating selections.
+// s.mu must be held, and is released.
+func (s *Store[M, A]) replace(m M) {
+	s.model = m
+	s.version++
+	version := s.version
+
+	selections := make([]selection[M], 0, len(s.selections))
+	for sel := range s.selections {
+		selections = append(selections, sel)
+	}
+	s.mu.Unlock()
+
+	for _, sel := range selections {
+		sel.update(version, m)
+	}
+}
+
+// A Selection is a part of the model of a Store,
+// picked out by a selector function.
+type Selection[S comparable] struct {
+	signal *reactive.Signal[S]
+	close  func()
+
+	// the latest version of the model selected, and the value
+	// selected from it; and whether a goroutine is setting the
+	// signal, and so will set it to latest before it finishes.
+	mu      sync.Mutex
+	version uint64
+	latest  S
+	setting bool
+}
+
+// Select returns a Selection of the model of s. The selected
+// value is the result of calling selector on the model, and
+// changes only when that result does.
+//
+// The Selection should be closed once it is no longer needed.
+func Select[M, A any, S comparable](s *Store[M, A], selector func(M) S) *Selection[S] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sel := &modelSelection[M, S]{
+		Selection: &Selection[S]{
+			signal:  reactive.NewSignal(selector(s.model)),
+			version: s.version,
+		},
+		selector: selector,
+	}
+
+	sel.close = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.selections, sel)
+	}
+
+	s.selections[sel] = struct{}{}
+
+	return sel.Selection
+}
+
+// Get returns the selected value. As with zemn.me/reactive
+// Signals, reading a Selection while rendering subscribes
+// the Component to changes.
+func (s *Selection[S]) Get() S { return s.signal.Get() }
+
+// Close stops the Selection from following the model.
+func (s *Selection[S]) Close() { s.close() }
+
+// a modelSelection is a Selection which knows
+// the type of the model.
+type modelSelection[M any, S comparable] struct {
+	*Selection[S]
+	selector func(M) S
+}
+
+// update selects from model. The signal is set without s.mu held,
+// as setting it may re-render Components which dispatch actions; an
+// update made meanwhile is left to the goroutine already setting it.
+func (s *modelSelection[M, S]) update(version uint64, model M) {
+	v := s.selector(model)
+
+	s.mu.Lock()
+
+	// a newer model has already been selected
+	if version <= s.version {
+		s.mu.Unlock()
+		return
+	}
+
+	s.version, s.latest = version, v
+	if s.setting {
+		s.mu.Unlock()
+		return
+	}
+
+	s.setting = true
+	s.mu.Unlock()
+
+	for {
+		s.signal.Set(v)
+
+		s.mu.Lock()
+		if s.version == version {
+			s.setting = false
+			s.mu.Unlock()
+			return
+		}
+
+		version, v = s.version, s.latest
+		s.mu.Unlock()
+	}
+}
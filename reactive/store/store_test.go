@@ -0,0 +1,149 @@
+package store_test
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive"
+	"zemn.me/reactive/store"
+	"zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+type model struct {
+	Count int
+	Name  string
+}
+
+type action struct {
+	Add  int
+	Name string
+}
+
+func reduce(m model, a action) model {
+	m.Count += a.Add
+	if a.Name != "" {
+		m.Name = a.Name
+	}
+	return m
+}
+
+// counter is a StaticComponent which renders
+// a selection of the count.
+type counter struct {
+	*treetest.StaticComponent
+	count *store.Selection[int]
+	seen  *[]int
+}
+
+func (c counter) Render() ([]tree.Component, error) {
+	*c.seen = append(*c.seen, c.count.Get())
+	return c.StaticComponent.Render()
+}
+
+var _ = Describe("Store", func() {
+	var s *store.Store[model, action]
+
+	BeforeEach(func() {
+		s = store.New(model{Name: "a"}, reduce)
+	})
+
+	It("should hold the initial model", func() {
+		Expect(s.Model()).To(Equal(model{Name: "a"}))
+	})
+
+	It("should reduce dispatched actions", func() {
+		s.Dispatch(action{Add: 2})
+		s.Dispatch(action{Add: 3, Name: "b"})
+		Expect(s.Model()).To(Equal(model{Count: 5, Name: "b"}))
+	})
+
+	It("should accept actions from any goroutine", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.Dispatch(action{Add: 1})
+			}()
+		}
+		wg.Wait()
+
+		Expect(s.Model().Count).To(Equal(100))
+	})
+
+	It("should pass actions through middleware in order", func() {
+		var calls []string
+		mw := func(name string) store.Middleware[model, action] {
+			return func(_ *store.Store[model, action], next func(action)) func(action) {
+				return func(a action) {
+					calls = append(calls, name)
+					next(a)
+				}
+			}
+		}
+
+		s = store.New(model{}, reduce, mw("first"), store.Log[model, action], mw("second"))
+		s.Dispatch(action{Add: 1})
+
+		Expect(calls).To(Equal([]string{"first", "second"}))
+		Expect(s.Model().Count).To(Equal(1))
+	})
+
+	Describe("Select", func() {
+		var (
+			count *store.Selection[int]
+			seen  []int
+			rec   treetest.Recorder
+		)
+
+		BeforeEach(func() {
+			seen = nil
+			rec.Clear()
+			count = store.Select(s, func(m model) int { return m.Count })
+			tree.NewNode(counter{&treetest.StaticComponent{Id: "counter"}, count, &seen}, &rec)
+		})
+
+		AfterEach(func() { count.Close() })
+
+		It("should select from the model", func() {
+			Expect(count.Get()).To(Equal(0))
+			s.Dispatch(action{Add: 4})
+			Expect(count.Get()).To(Equal(4))
+		})
+
+		It("should re-render Components when the selected value changes", func() {
+			s.Dispatch(action{Add: 1})
+			Expect(seen).To(Equal([]int{0, 1}))
+		})
+
+		It("should not re-render Components when something else changes", func() {
+			s.Dispatch(action{Name: "b"})
+			Expect(seen).To(Equal([]int{0}))
+		})
+
+		It("should allow actions to be dispatched while updating", func(done Done) {
+			defer close(done)
+
+			e := reactive.NewEffect(func() {
+				if count.Get() == 1 {
+					s.Dispatch(action{Add: 1})
+				}
+			})
+			defer e.Stop()
+
+			s.Dispatch(action{Add: 1})
+			Expect(count.Get()).To(Equal(2))
+			Expect(seen).To(Equal([]int{0, 1, 2}))
+		})
+
+		It("should stop following the model once closed", func() {
+			count.Close()
+			s.Dispatch(action{Add: 1})
+			Expect(count.Get()).To(Equal(0))
+			Expect(seen).To(Equal([]int{0}))
+		})
+	})
+})
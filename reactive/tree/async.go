@@ -0,0 +1,118 @@
+package tree
+
+import (
+	"context"
+	"sync"
+
+	"zemn.me/debug"
+)
+
+var _ Component = &Async{}
+
+// An Async is a Component which renders the result of loading
+// something in the background, such as reading a file or running
+// a command.
+//
+// When mounted, an Async calls Load in a new goroutine. Until Load
+// returns, the Async renders Fallback. If Load fails, it renders
+// the result of Failed. Otherwise, it renders the Component returned by
+// Load. If the Async is closed before Load returns, the Context passed
+// to Load is cancelled and the result discarded.
+//
+// The state of the load is kept by the Node the Async is rendered at,
+// so a parent may render a new *Async each time; it carries on the
+// load of the one it replaces. To load again, the parent should
+// replace the Async with nil and then with a new Async.
+type Async struct {
+	Load     func(ctx context.Context) (Component, error)
+	Fallback Component
+	Failed   func(err error) Component
+
+	// shared with the Asyncs this
+	// one replaces, or replaced by.
+	load *asyncLoad
+}
+
+// an asyncLoad is the state of
+// the load of an Async.
+type asyncLoad struct {
+	mu     sync.Mutex
+	done   bool
+	result Component
+	err    error
+	cancel context.CancelFunc
+}
+
+var _ successor = &Async{}
+
+func (*Async) Name() string { return "async" }
+
+func (a *Async) succeed(old Component) {
+	if o, ok := old.(*Async); ok {
+		a.load = o.load
+	}
+}
+
+func (a *Async) Mount(s StateController) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	l := &asyncLoad{cancel: cancel}
+	a.load = l
+
+	go func() {
+		result, err := a.Load(ctx)
+
+		// closed while loading
+		if ctx.Err() != nil {
+			debug.Log("async load cancelled")
+			return
+		}
+
+		l.mu.Lock()
+		l.done, l.result, l.err = true, result, err
+		l.mu.Unlock()
+
+		s.Update()
+	}()
+}
+
+func (a *Async) Close() {
+	if a.load != nil {
+		a.load.cancel()
+	}
+}
+
+// ShouldUpdate only reports true if old is a different Component;
+// the Async updates itself when Load returns.
+func (a *Async) ShouldUpdate(old Component) (bool, error) {
+	return old != Component(a), nil
+}
+
+// Render returns three children: the fallback, the loaded
+// Component, and the error Component, of which at most one
+// is non-nil.
+func (a *Async) Render() ([]Component, error) {
+	// not yet mounted
+	if a.load == nil {
+		return []Component{a.Fallback, nil, nil}, nil
+	}
+
+	l := a.load
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case !l.done:
+		return []Component{a.Fallback, nil, nil}, nil
+	case l.err != nil:
+		var failed Component
+		if a.Failed != nil {
+			failed = a.Failed(l.err)
+		}
+
+		return []Component{nil, nil, failed}, nil
+	}
+
+	return []Component{nil, l.result, nil}, nil
+}
@@ -0,0 +1,155 @@
+package tree_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+var _ = Describe("Async", func() {
+	var (
+		s         *Scheduler
+		rec       treetest.Recorder
+		root      *treetest.StaticComponent
+		fallback  *treetest.StaticComponent
+		loaded    *treetest.StaticComponent
+		failed    *treetest.StaticComponent
+		release   chan error
+		cancelled chan bool
+		returned  chan bool
+		rootNode  *Node
+		newAsync  func() *Async
+	)
+
+	BeforeEach(func() {
+		rec.Clear()
+		release = make(chan error)
+		cancelled = make(chan bool, 1)
+		returned = make(chan bool)
+
+		fallback = &treetest.StaticComponent{Id: "fallback"}
+		loaded = &treetest.StaticComponent{Id: "loaded"}
+		failed = &treetest.StaticComponent{Id: "failed"}
+
+		load := func(ctx context.Context) (Component, error) {
+			defer close(returned)
+
+			select {
+			case <-ctx.Done():
+				cancelled <- true
+				return nil, ctx.Err()
+			case err := <-release:
+				return loaded, err
+			}
+		}
+
+		newAsync = func() *Async {
+			return &Async{
+				Load:     load,
+				Fallback: fallback,
+				Failed:   func(error) Component { return failed },
+			}
+		}
+
+		root = &treetest.StaticComponent{Id: "root"}
+		root.Children = []Component{newAsync()}
+
+		s = NewScheduler()
+		rootNode = s.NewNode(root, &rec)
+		s.Flush()
+	})
+
+	// the loader must not outlive the spec,
+	// or it would see the next one's channels.
+	AfterEach(func() {
+		rootNode.Unmount()
+		Eventually(returned).Should(BeClosed())
+	})
+
+	It("should render the fallback while loading", func() {
+		Expect(fallback.MountCalls).To(HaveLen(1))
+		Expect(loaded.MountCalls).To(HaveLen(0))
+	})
+
+	When("the load succeeds", func() {
+		BeforeEach(func() {
+			release <- nil
+			Eventually(s.Pending).Should(Equal(1))
+			s.Flush()
+		})
+
+		It("should replace the fallback with the result", func() {
+			Expect(fallback.CloseCalls).To(HaveLen(1))
+			Expect(loaded.MountCalls).To(HaveLen(1))
+			Expect(failed.MountCalls).To(HaveLen(0))
+		})
+	})
+
+	When("its parent renders a new Async before the load succeeds", func() {
+		BeforeEach(func() {
+			root.Children = []Component{newAsync()}
+			root.ForceUpdate()
+			s.Flush()
+
+			release <- nil
+			Eventually(s.Pending).Should(Equal(1))
+			s.Flush()
+		})
+
+		It("should carry on the load", func() {
+			Expect(fallback.CloseCalls).To(HaveLen(1))
+			Expect(loaded.MountCalls).To(HaveLen(1))
+		})
+	})
+
+	When("its parent renders a new Async, then closes it", func() {
+		BeforeEach(func() {
+			root.Children = []Component{newAsync()}
+			root.ForceUpdate()
+			s.Flush()
+
+			root.Children = []Component{nil}
+			root.ForceUpdate()
+			s.Flush()
+		})
+
+		It("should cancel the load", func() {
+			Eventually(cancelled).Should(Receive())
+		})
+	})
+
+	When("the load fails", func() {
+		BeforeEach(func() {
+			release <- errors.New("oh no")
+			Eventually(s.Pending).Should(Equal(1))
+			s.Flush()
+		})
+
+		It("should replace the fallback with the error Component", func() {
+			Expect(fallback.CloseCalls).To(HaveLen(1))
+			Expect(loaded.MountCalls).To(HaveLen(0))
+			Expect(failed.MountCalls).To(HaveLen(1))
+		})
+	})
+
+	When("closed before the load finishes", func() {
+		BeforeEach(func() {
+			root.Children = []Component{nil}
+			root.ForceUpdate()
+			s.Flush()
+		})
+
+		It("should cancel the load", func() {
+			Eventually(cancelled).Should(Receive())
+		})
+
+		It("should not request an update", func() {
+			Consistently(s.Pending).Should(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,193 @@
+package tree
+
+import (
+	"fmt"
+	"strings"
+
+	"zemn.me/debug"
+)
+
+// DefaultCascadeLimit is the most updates which may cascade
+// from a single update before the tree gives up, reporting a
+// CascadeError to the Mapper. It is the limit of trees whose
+// limit has not been set with SetCascadeLimit, or, for trees
+// with a Scheduler, by the Scheduler's CascadeLimit.
+//
+// An update requested while a tree is updating, for example by a
+// Component calling Update() from its own Render or ShouldUpdate,
+// or by two Components which update each other, is not performed
+// until the current update has been committed. Each such update
+// cascades from the one before it, and an endless cascade is an
+// update loop.
+//
+// Updates requested while the tree is not updating, such as by
+// timers between updates, do not cascade; they start afresh. The
+// tree cannot tell which goroutine requested an update, so those
+// requested by other goroutines while it is updating are counted
+// as cascading from the update.
+const DefaultCascadeLimit = 50
+
+// A CascadeError is reported when more than the cascade limit of
+// updates cascade from a single update; see DefaultCascadeLimit.
+type CascadeError struct {
+	// the Paths of each Node updated,
+	// in the order they were updated
+	Cascade []Path
+}
+
+// cycle returns the distinct Paths of the Cascade,
+// in the order they first appear.
+func (c CascadeError) cycle() (paths []string) {
+	seen := make(map[string]bool)
+	for _, p := range c.Cascade {
+		s := p.String()
+		if seen[s] {
+			continue
+		}
+
+		seen[s] = true
+		paths = append(paths, s)
+	}
+
+	return
+}
+
+func (c CascadeError) Error() string {
+	return fmt.Sprintf(
+		"more than %d updates cascaded from one update;"+
+			" Components are probably requesting updates while"+
+			" rendering. Nodes updating each other: %s",
+		len(c.Cascade)-1,
+		strings.Join(c.cycle(), ", "),
+	)
+}
+
+// a deferral is an update requested, or a function
+// passed to Do, while a tree without a Scheduler
+// was updating.
+type deferral struct {
+	node *Node
+
+	// the Paths of the Nodes whose updates
+	// led to this one; see DefaultCascadeLimit.
+	cascade []Path
+
+	// if non-nil, the function passed to
+	// Do, which is called instead.
+	do func()
+}
+
+// SetCascadeLimit sets the most updates which may cascade from
+// a single update of this Node's tree, overriding DefaultCascadeLimit
+// and the CascadeLimit of its Scheduler, if any. A limit of zero
+// restores the default.
+func (n *Node) SetCascadeLimit(limit int) {
+	root := n.Root()
+
+	root.updateMu.Lock()
+	defer root.updateMu.Unlock()
+
+	root.limit = limit
+}
+
+// cascadeLimit returns the cascade limit of this
+// root, or fallback if it has not been set.
+func (n *Node) cascadeLimit(fallback int) int {
+	n.updateMu.Lock()
+	defer n.updateMu.Unlock()
+
+	if n.limit != 0 {
+		return n.limit
+	}
+
+	return fallback
+}
+
+// updateNow performs an update of this Node immediately, performing
+// any updates requested while updating afterwards.
+//
+// If called while the tree is already updating, the update
+// is deferred until that update has been committed.
+func (n *Node) updateNow() { n.exclusively(deferral{node: n}) }
+
+// exclusively performs d, then anything deferred meanwhile, unless
+// the tree is already updating, in which case d is deferred.
+func (n *Node) exclusively(d deferral) {
+	root := n.Root()
+
+	root.updateMu.Lock()
+	if root.updating {
+		debug.Log("%s requested an update while updating; deferring it", n.Path())
+
+		for _, other := range root.deferred {
+			if d.do == nil && other.do == nil && other.node == n {
+				root.updateMu.Unlock()
+				return
+			}
+		}
+
+		if d.do == nil {
+			d.cascade = append(append([]Path(nil), root.cascade...), n.Path())
+		}
+
+		root.deferred = append(root.deferred, d)
+		root.updateMu.Unlock()
+		return
+	}
+
+	root.updating, root.cascade = true, nil
+	root.updateMu.Unlock()
+
+	// if an update panics, the tree
+	// must not be left updating forever.
+	defer func() {
+		root.updateMu.Lock()
+		defer root.updateMu.Unlock()
+
+		root.updating, root.cascade, root.deferred = false, nil, nil
+	}()
+
+	d.perform()
+
+	for {
+		root.updateMu.Lock()
+		if len(root.deferred) == 0 {
+			root.updateMu.Unlock()
+			return
+		}
+
+		next := root.deferred[0]
+		root.deferred = root.deferred[1:]
+
+		limit := root.limit
+		if limit == 0 {
+			limit = DefaultCascadeLimit
+		}
+
+		if len(next.cascade) > limit {
+			root.updateMu.Unlock()
+			next.node.report(CascadeError{Cascade: next.cascade})
+			return
+		}
+
+		if next.do == nil {
+			root.cascade = next.cascade
+		}
+		root.updateMu.Unlock()
+
+		if next.do == nil && next.node.Component == nil {
+			continue
+		}
+
+		next.perform()
+	}
+}
+
+func (d deferral) perform() {
+	if d.do != nil {
+		d.do()
+		return
+	}
+
+	d.node.report(d.node.update(nil))
+}
@@ -0,0 +1,231 @@
+package tree_test
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+var _ = Describe("Cascading updates", func() {
+	var (
+		rec      treetest.Recorder
+		a, b     *treetest.StaticComponent
+		aUpdates func()
+		bUpdates func()
+		root     *treetest.StaticComponent
+	)
+
+	BeforeEach(func() {
+		rec.Clear()
+
+		aUpdates, bUpdates = nil, nil
+		a = &treetest.StaticComponent{Id: "a"}
+		b = &treetest.StaticComponent{Id: "b"}
+		root = &treetest.StaticComponent{Id: "root", Children: []Component{
+			eagerComponent{a, func() {
+				if aUpdates != nil {
+					aUpdates()
+				}
+			}},
+			eagerComponent{b, func() {
+				if bUpdates != nil {
+					bUpdates()
+				}
+			}},
+		}}
+	})
+
+	cascadeErrors := func() (errs []CascadeError) {
+		for _, e := range rec.Errors {
+			if c, ok := e.Err.(CascadeError); ok {
+				errs = append(errs, c)
+			}
+		}
+		return
+	}
+
+	When("a Component updates itself while rendering", func() {
+		BeforeEach(func() {
+			NewNode(root, &rec)
+			aUpdates = func() { a.ForceUpdate() }
+			a.ForceUpdate()
+		})
+
+		It("should stop after DefaultCascadeLimit updates", func() {
+			// the first render, the update, and the cascades
+			Expect(a.RenderCalls).To(HaveLen(1 + 1 + DefaultCascadeLimit))
+		})
+
+		It("should report the loop", func() {
+			Expect(cascadeErrors()).To(HaveLen(1))
+			Expect(cascadeErrors()[0].Error()).To(ContainSubstring("root/0"))
+		})
+	})
+
+	When("a Component updates another once while rendering", func() {
+		BeforeEach(func() {
+			NewNode(root, &rec)
+			aUpdates = func() {
+				aUpdates = nil
+				b.ForceUpdate()
+			}
+			a.ForceUpdate()
+		})
+
+		It("should update the other Component after committing", func() {
+			Expect(b.RenderCalls).To(HaveLen(2))
+			Expect(rec.Errors).To(HaveLen(0))
+		})
+	})
+
+	When("two Components update each other", func() {
+		BeforeEach(func() {
+			NewNode(root, &rec)
+			aUpdates = func() { b.ForceUpdate() }
+			bUpdates = func() { a.ForceUpdate() }
+			a.ForceUpdate()
+		})
+
+		It("should report both Nodes in the loop", func() {
+			Expect(cascadeErrors()).To(HaveLen(1))
+			Expect(cascadeErrors()[0].Error()).To(ContainSubstring("root/1, root/0"))
+		})
+	})
+
+	When("its cascade limit is set", func() {
+		BeforeEach(func() {
+			n := NewNode(root, &rec)
+			n.SetCascadeLimit(3)
+			aUpdates = func() { a.ForceUpdate() }
+			a.ForceUpdate()
+		})
+
+		It("should stop after that many updates", func() {
+			Expect(a.RenderCalls).To(HaveLen(1 + 1 + 3))
+			Expect(cascadeErrors()).To(HaveLen(1))
+		})
+	})
+
+	When("an update panics", func() {
+		BeforeEach(func() {
+			NewNode(root, &rec)
+			aUpdates = func() {
+				aUpdates = nil
+				panic("oops")
+			}
+			Expect(func() { a.ForceUpdate() }).To(Panic())
+		})
+
+		It("should still perform later updates", func() {
+			a.ForceUpdate()
+			Expect(a.RenderCalls).To(HaveLen(2))
+			Expect(rec.Errors).To(HaveLen(0))
+		})
+	})
+
+	When("updated by other goroutines while updating", func() {
+		BeforeEach(func() {
+			NewNode(root, &rec)
+		})
+
+		It("should perform every update, one at a time", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					a.ForceUpdate()
+				}()
+			}
+
+			wg.Wait()
+			Expect(len(a.RenderCalls)).To(BeNumerically(">", 1))
+			Expect(rec.Errors).To(HaveLen(0))
+		})
+	})
+
+	When("a function is passed to Do while updating", func() {
+		var called []int
+
+		BeforeEach(func() {
+			called = nil
+			n := NewNode(root, &rec)
+			aUpdates = func() {
+				aUpdates = nil
+				n.Do(func() { called = append(called, len(a.RenderCalls)) })
+				Expect(called).To(BeEmpty())
+			}
+			a.ForceUpdate()
+		})
+
+		It("should be called once the update has been committed", func() {
+			Expect(called).To(Equal([]int{2}))
+			Expect(rec.Errors).To(HaveLen(0))
+		})
+	})
+
+	When("using a Scheduler", func() {
+		var s *Scheduler
+
+		BeforeEach(func() {
+			s = NewScheduler()
+			s.CascadeLimit = 5
+			s.NewNode(root, &rec)
+			s.Flush()
+		})
+
+		It("should not count updates requested between updates as cascades", func() {
+			// a ticker whose ticks land between updates
+			tick := make(chan bool)
+			aUpdates = func() {
+				go func() {
+					b.ForceUpdate()
+					tick <- true
+				}()
+			}
+
+			bUpdates = func() { a.ForceUpdate() }
+			a.ForceUpdate()
+
+			for i := 0; i < 20; i++ {
+				Expect(s.Step()).To(BeTrue())
+				if i%2 == 0 {
+					<-tick
+				}
+			}
+
+			Expect(rec.Errors).To(HaveLen(0))
+		})
+	})
+
+	When("using a Scheduler with a loop", func() {
+		var s *Scheduler
+
+		BeforeEach(func() {
+			s = NewScheduler()
+			s.CascadeLimit = 5
+			s.NewNode(root, &rec)
+			s.Flush()
+
+			aUpdates = func() { b.ForceUpdate() }
+			bUpdates = func() { a.ForceUpdate() }
+			a.ForceUpdate()
+			s.Flush()
+		})
+
+		It("should stop updating", func() {
+			Expect(s.Pending()).To(Equal(0))
+			Expect(a.RenderCalls).To(HaveLen(1 + 3))
+			Expect(b.RenderCalls).To(HaveLen(1 + 3))
+		})
+
+		It("should report the loop", func() {
+			Expect(cascadeErrors()).To(HaveLen(1))
+			Expect(cascadeErrors()[0].Cascade).To(HaveLen(6))
+		})
+	})
+})
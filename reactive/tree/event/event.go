@@ -0,0 +1,13 @@
+// Package event names the kinds of call made to a tree.Mapper,
+// for Mappers which record them, such as treetest.Recorder and
+// the Mapper of a store.History.
+package event // import "zemn.me/reactive/tree/event"
+
+// A Kind is the kind of call made to a Mapper.
+type Kind string
+
+const (
+	Map   Kind = "map"
+	UnMap Kind = "unmap"
+	Error Kind = "error"
+)
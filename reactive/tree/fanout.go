@@ -0,0 +1,352 @@
+package tree
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"zemn.me/debug"
+)
+
+var (
+	_ Committer  = &FanOut{}
+	_ PathMapper = &FanOut{}
+)
+
+// An Output is one of the Mappers a FanOut forwards to.
+type Output struct {
+	Mapper
+
+	// If Types is not empty, only calls about Components
+	// of the same type as one of Types are forwarded.
+	Types []Component
+
+	// If Isolated, calls are queued and made from a separate
+	// goroutine, so a slow Mapper does not hold up the tree or
+	// the other Outputs, and panics in the Mapper are recovered
+	// and passed to Failed.
+	//
+	// Outputs which are not Isolated are called on the goroutine
+	// updating the tree, so a panic in their Mapper is not
+	// recovered, and unwinds the update.
+	Isolated bool
+
+	// Queue is the most calls that may be queued for an Isolated
+	// Output; if zero, DefaultQueue. Once it is full, the oldest
+	// frame queued, up to and including its Commit, is dropped to
+	// make room, so a Mapper that falls that far behind misses
+	// updates rather than holding up the tree.
+	Queue int
+
+	// Failed is called, on the goroutine of an Isolated Output,
+	// with a PanicError if its Mapper panics, and a DroppedError
+	// if calls to it are dropped. If nil, the FanOut keeps the
+	// first such error, to be returned by Err.
+	Failed func(err error)
+}
+
+// DefaultQueue is the Queue of an
+// Isolated Output which does not set one.
+const DefaultQueue = 1024
+
+// A PanicError is passed to Failed when the
+// Mapper of an Isolated Output panics.
+type PanicError struct {
+	// what the Mapper panicked with
+	Value interface{}
+}
+
+func (p PanicError) Error() string {
+	return fmt.Sprintf("isolated Mapper panicked: %v", p.Value)
+}
+
+// A DroppedError is passed to Failed when calls to the Mapper
+// of an Isolated Output are dropped because its Queue is full.
+type DroppedError struct {
+	// the number of calls dropped
+	Calls int
+}
+
+func (d DroppedError) Error() string {
+	return fmt.Sprintf("isolated Mapper fell behind; dropped %d calls", d.Calls)
+}
+
+// A FanOut is a Mapper which forwards each call to several Outputs,
+// so that one tree can be mapped to several places at once.
+// If an Output's Mapper is a Committer, it is also told of each
+// Commit.
+type FanOut struct {
+	outputs []*output
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewFanOut returns a FanOut forwarding to outputs, starting
+// a goroutine for each that is Isolated. Close stops them.
+func NewFanOut(outputs ...Output) (f *FanOut) {
+	f = &FanOut{outputs: make([]*output, len(outputs))}
+
+	for i, o := range outputs {
+		f.outputs[i] = &output{Output: o, fanOut: f}
+		f.outputs[i].cond = sync.NewCond(&f.outputs[i].mu)
+
+		if o.Isolated {
+			go f.outputs[i].run()
+		}
+	}
+
+	return
+}
+
+func (f *FanOut) Map(c Component)   { f.each(c, func(m Mapper) { m.Map(c) }) }
+func (f *FanOut) UnMap(c Component) { f.each(c, func(m Mapper) { m.UnMap(c) }) }
+func (f *FanOut) Error(c Component, err error) {
+	f.each(c, func(m Mapper) { m.Error(c, err) })
+}
+
+func (f *FanOut) MapPath(p Path, c Component) {
+	f.each(c, func(m Mapper) {
+		if pm, ok := m.(PathMapper); ok {
+			pm.MapPath(p, c)
+		} else {
+			m.Map(c)
+		}
+	})
+}
+
+func (f *FanOut) UnMapPath(p Path, c Component) {
+	f.each(c, func(m Mapper) {
+		if pm, ok := m.(PathMapper); ok {
+			pm.UnMapPath(p, c)
+		} else {
+			m.UnMap(c)
+		}
+	})
+}
+
+func (f *FanOut) ErrorPath(p Path, c Component, err error) {
+	f.each(c, func(m Mapper) {
+		if pm, ok := m.(PathMapper); ok {
+			pm.ErrorPath(p, c, err)
+		} else {
+			m.Error(c, err)
+		}
+	})
+}
+
+func (f *FanOut) Commit() {
+	for _, o := range f.outputs {
+		// Isolated Outputs need to know where
+		// frames end, to drop them whole.
+		c, ok := o.Mapper.(Committer)
+		switch {
+		case ok:
+			o.do(queued{call: c.Commit, commit: true})
+		case o.Isolated:
+			o.do(queued{commit: true})
+		}
+	}
+}
+
+// Flush waits for every Isolated Output to finish
+// the calls queued for it.
+func (f *FanOut) Flush() {
+	for _, o := range f.outputs {
+		o.mu.Lock()
+		for len(o.queue) > 0 || o.dropped > 0 || o.busy {
+			o.cond.Wait()
+		}
+		o.mu.Unlock()
+	}
+}
+
+// Err returns the first error of an Isolated Output
+// without a Failed function, if any.
+func (f *FanOut) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err
+}
+
+// Close stops the goroutines of the Isolated Outputs once they
+// have finished the calls queued for them. Calls made after
+// Close are dropped.
+func (f *FanOut) Close() {
+	for _, o := range f.outputs {
+		o.mu.Lock()
+		o.closed = true
+		o.cond.Broadcast()
+		o.mu.Unlock()
+	}
+}
+
+func (f *FanOut) each(c Component, call func(Mapper)) {
+	for _, o := range f.outputs {
+		if m := o.Mapper; o.accepts(c) {
+			o.do(queued{call: func() { call(m) }})
+		}
+	}
+}
+
+// a queued call to the Mapper of an Output.
+type queued struct {
+	// nil if this only marks the end of a frame
+	call func()
+
+	// whether this ends a frame
+	commit bool
+}
+
+type output struct {
+	Output
+	fanOut *FanOut
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []queued
+	busy   bool
+	closed bool
+
+	// calls dropped since the
+	// last DroppedError
+	dropped int
+}
+
+func (o *output) accepts(c Component) bool {
+	if len(o.Types) == 0 {
+		return true
+	}
+
+	t := reflect.TypeOf(c)
+	for _, example := range o.Types {
+		if reflect.TypeOf(example) == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// do makes q's call now, or queues it
+// if the Output is Isolated.
+func (o *output) do(q queued) {
+	if !o.Isolated {
+		if q.call != nil {
+			q.call()
+		}
+
+		return
+	}
+
+	limit := o.Queue
+	if limit <= 0 {
+		limit = DefaultQueue
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return
+	}
+
+	if len(o.queue) >= limit {
+		o.dropFrame()
+	}
+
+	o.queue = append(o.queue, q)
+	o.cond.Broadcast()
+}
+
+// dropFrame drops the oldest frame queued,
+// or everything queued if it has no end.
+func (o *output) dropFrame() {
+	n := len(o.queue)
+	for i, q := range o.queue {
+		if q.commit {
+			n = i + 1
+			break
+		}
+	}
+
+	for _, q := range o.queue[:n] {
+		if q.call != nil {
+			o.dropped++
+		}
+	}
+
+	debug.Log("isolated Mapper fell behind; dropping %d queued", n)
+	o.queue = append(o.queue[:0:0], o.queue[n:]...)
+}
+
+// run makes the calls queued for an Isolated
+// Output until it is closed.
+func (o *output) run() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for {
+		for len(o.queue) == 0 && o.dropped == 0 && !o.closed {
+			o.cond.Wait()
+		}
+
+		var call func()
+		switch {
+		case o.dropped > 0:
+			dropped := DroppedError{Calls: o.dropped}
+			o.dropped = 0
+			call = func() { o.fail(dropped) }
+		case len(o.queue) > 0:
+			call = o.queue[0].call
+			o.queue[0] = queued{}
+			o.queue = o.queue[1:]
+		default:
+			return
+		}
+
+		if call == nil {
+			o.cond.Broadcast()
+			continue
+		}
+
+		o.busy = true
+
+		o.mu.Unlock()
+		o.safely(call)
+		o.mu.Lock()
+
+		o.busy = false
+		o.cond.Broadcast()
+	}
+}
+
+func (o *output) safely(call func()) {
+	defer func() {
+		if v := recover(); v != nil {
+			o.fail(PanicError{Value: v})
+		}
+	}()
+
+	call()
+}
+
+// fail passes err to Failed, or
+// failing that, to the FanOut.
+func (o *output) fail(err error) {
+	debug.Log("%s", err)
+
+	if o.Failed != nil {
+		o.Failed(err)
+		return
+	}
+
+	f := o.fanOut
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err == nil {
+		f.err = err
+	}
+}
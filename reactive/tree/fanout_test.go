@@ -0,0 +1,171 @@
+package tree_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+// a panickingMapper panics whenever it is called.
+type panickingMapper struct{}
+
+func (panickingMapper) Map(Component)          { panic("map") }
+func (panickingMapper) UnMap(Component)        { panic("unmap") }
+func (panickingMapper) Error(Component, error) { panic("error") }
+
+// a blockingMapper waits for each call to be let through.
+type blockingMapper struct {
+	treetest.Recorder
+	gate chan struct{}
+}
+
+func (b *blockingMapper) Map(c Component) {
+	<-b.gate
+	b.Recorder.Map(c)
+}
+
+func (b *blockingMapper) MapPath(p Path, c Component) {
+	<-b.gate
+	b.Recorder.MapPath(p, c)
+}
+
+var _ = Describe("FanOut", func() {
+	var (
+		root, a, b *treetest.StaticComponent
+		first      treetest.Recorder
+		second     treetest.Recorder
+	)
+
+	BeforeEach(func() {
+		first.Clear()
+		second.Clear()
+
+		a = &treetest.StaticComponent{Id: "a"}
+		b = &treetest.StaticComponent{Id: "b"}
+		root = &treetest.StaticComponent{Id: "root", Children: []Component{
+			eagerComponent{StaticComponent: a},
+			b,
+		}}
+	})
+
+	It("should forward to every Output", func() {
+		f := NewFanOut(Output{Mapper: &first}, Output{Mapper: &second})
+		defer f.Close()
+
+		NewNode(root, f)
+
+		Expect(first.Components).To(HaveLen(3))
+		Expect(second.Components).To(Equal(first.Components))
+	})
+
+	It("should only forward Components of the Types an Output asks for", func() {
+		f := NewFanOut(
+			Output{Mapper: &first},
+			Output{Mapper: &second, Types: []Component{eagerComponent{}}},
+		)
+		defer f.Close()
+
+		NewNode(root, f)
+
+		Expect(first.Components).To(HaveLen(3))
+		Expect(second.Components).To(Equal([]Component{eagerComponent{StaticComponent: a}}))
+	})
+
+	It("should recover panics in Isolated Outputs", func() {
+		var failures []error
+		f := NewFanOut(
+			Output{Mapper: panickingMapper{}, Isolated: true, Failed: func(err error) {
+				failures = append(failures, err)
+			}},
+			Output{Mapper: &first},
+		)
+		defer f.Close()
+
+		Expect(func() { NewNode(root, f) }).ToNot(Panic())
+		f.Flush()
+
+		Expect(failures).To(Equal([]error{
+			PanicError{Value: "map"},
+			PanicError{Value: "map"},
+			PanicError{Value: "map"},
+		}))
+		Expect(first.Components).To(HaveLen(3))
+		Expect(f.Err()).ToNot(HaveOccurred())
+	})
+
+	It("should keep the first failure of Outputs without Failed", func() {
+		f := NewFanOut(Output{Mapper: panickingMapper{}, Isolated: true})
+		defer f.Close()
+
+		NewNode(root, f)
+		f.Flush()
+
+		Expect(f.Err()).To(Equal(PanicError{Value: "map"}))
+	})
+
+	It("should not recover panics in Outputs which are not Isolated", func() {
+		f := NewFanOut(Output{Mapper: panickingMapper{}})
+		defer f.Close()
+
+		Expect(func() { NewNode(root, f) }).To(Panic())
+	})
+
+	It("should not wait for slow Isolated Outputs", func(done Done) {
+		slow := &blockingMapper{gate: make(chan struct{})}
+		f := NewFanOut(
+			Output{Mapper: slow, Isolated: true},
+			Output{Mapper: &first},
+		)
+		defer f.Close()
+
+		NewNode(root, f)
+		Expect(first.Components).To(HaveLen(3))
+		Expect(slow.Components).To(HaveLen(0))
+
+		close(slow.gate)
+		f.Flush()
+
+		Expect(slow.Components).To(Equal(first.Components))
+		close(done)
+	})
+
+	It("should drop the oldest frame once the queue of an Isolated Output is full", func(done Done) {
+		var failures []error
+		slow := &blockingMapper{gate: make(chan struct{})}
+		f := NewFanOut(Output{Mapper: slow, Isolated: true, Queue: 1, Failed: func(err error) {
+			failures = append(failures, err)
+		}})
+		defer f.Close()
+
+		// three Maps and a Commit, but at most
+		// one call is made and one queued.
+		NewNode(root, f)
+
+		close(slow.gate)
+		f.Flush()
+
+		Expect(len(slow.Components)).To(BeNumerically("<", 3))
+		Expect(failures).ToNot(BeEmpty())
+
+		dropped := 0
+		for _, err := range failures {
+			Expect(err).To(BeAssignableToTypeOf(DroppedError{}))
+			dropped += err.(DroppedError).Calls
+		}
+
+		Expect(dropped + len(slow.Components)).To(Equal(3))
+		close(done)
+	})
+
+	It("should drop calls made after Close", func() {
+		f := NewFanOut(Output{Mapper: &first, Isolated: true})
+		f.Close()
+
+		NewNode(root, f)
+		f.Flush()
+
+		Expect(first.Components).To(HaveLen(0))
+	})
+})
@@ -0,0 +1,157 @@
+package tree
+
+import "sync"
+
+// A Focuser is a StateController which can give its Component
+// focus. Only one Node in a tree has focus at a time; what focus
+// means is up to the Mapper, but it is usually where keyboard input
+// goes first.
+//
+// The StateController a Node passes to Mount is a Focuser.
+type Focuser interface {
+	StateController
+
+	// Focus gives the Component focus.
+	Focus()
+
+	// HasFocus reports whether the Component has focus.
+	HasFocus() bool
+}
+
+var _ Focuser = &Node{}
+
+// focus is the state of focus
+// held by the root of a tree.
+type focus struct {
+	sync.Mutex
+	node *Node
+}
+
+// focus returns the focus state of this Node's tree.
+func (n *Node) focus() *focus {
+	root := n.Root()
+
+	root.updateMu.Lock()
+	defer root.updateMu.Unlock()
+
+	// roots made by NewNode already
+	// have it.
+	if root.focused == nil {
+		root.focused = new(focus)
+	}
+
+	return root.focused
+}
+
+// Focus gives this Node focus, updating it and the Node which
+// had focus before at InputPriority, so that they can show it.
+func (n *Node) Focus() { n.focusOn(n) }
+
+// focusOn gives focus to the Node to.
+func (n *Node) focusOn(to *Node) {
+	f := n.focus()
+
+	f.Lock()
+	from := f.node
+	f.node = to
+	f.Unlock()
+
+	if from == to {
+		return
+	}
+
+	for _, node := range []*Node{from, to} {
+		if node != nil && node.Component != nil {
+			node.UpdatePriority(InputPriority)
+		}
+	}
+}
+
+// HasFocus reports whether this Node has focus.
+func (n *Node) HasFocus() bool { return n.Focused() == n }
+
+// Focused returns the Node with focus in this
+// Node's tree, or nil if none has it.
+func (n *Node) Focused() *Node {
+	f := n.focus()
+
+	f.Lock()
+	defer f.Unlock()
+
+	return f.node
+}
+
+// blur takes focus from this Node, if it has it,
+// without updating it, as it is being closed.
+func (n *Node) blur() {
+	f := n.focus()
+
+	f.Lock()
+	defer f.Unlock()
+
+	if f.node == n {
+		f.node = nil
+	}
+}
+
+// MoveFocus gives focus to the Node delta places after the one with
+// focus, amongst the Nodes of this tree for which focusable returns
+// true, in the order Walk visits them, wrapping around at either end.
+// If no Node has focus, it goes to the first, or for negative delta
+// the last. MoveFocus returns the Node given focus, or nil if no Node
+// is focusable.
+func (n *Node) MoveFocus(delta int, focusable func(*Node) bool) *Node {
+	candidates := n.Root().FindAll(focusable)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	i, focused := -1, n.Focused()
+	for j, c := range candidates {
+		if c == focused {
+			i = j
+			break
+		}
+	}
+
+	switch {
+	case i != -1:
+		i += delta
+	case delta < 0:
+		i = len(candidates) + delta
+	default:
+		i = delta - 1
+	}
+
+	i %= len(candidates)
+	if i < 0 {
+		i += len(candidates)
+	}
+
+	to := candidates[i]
+	n.focusOn(to)
+
+	return to
+}
+
+// Bubble calls handle for the Node with focus, then each of its
+// ancestors in turn until handle returns true, reporting whether it
+// did. If no Node has focus, it starts at the root.
+func (n *Node) Bubble(handle func(*Node) bool) bool {
+	start := n.Focused()
+	if start == nil {
+		start = n.Root()
+	}
+
+	for node := start; node != nil; node = node.parent {
+		if node.Component == nil {
+			continue
+		}
+
+		if handle(node) {
+			return true
+		}
+	}
+
+	return false
+}
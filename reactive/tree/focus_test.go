@@ -0,0 +1,120 @@
+package tree_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+var _ = Describe("Focus", func() {
+	var (
+		rec         treetest.Recorder
+		root, a, b  *treetest.StaticComponent
+		c           *treetest.StaticComponent
+		rootNode    *Node
+		nodeOf      func(s *treetest.StaticComponent) *Node
+		notRoot     func(*Node) bool
+		visitedFrom func(start *Node) []string
+	)
+
+	nodeOf = func(s *treetest.StaticComponent) *Node {
+		return s.MountCalls[len(s.MountCalls)-1].StateController.(*Node)
+	}
+
+	notRoot = func(n *Node) bool { return n.Parent() != nil }
+
+	visitedFrom = func(n *Node) (visited []string) {
+		n.Bubble(func(n *Node) bool {
+			visited = append(visited, n.Path().String())
+			return false
+		})
+
+		return
+	}
+
+	BeforeEach(func() {
+		rec.Clear()
+		root = &treetest.StaticComponent{Id: "root"}
+		a = &treetest.StaticComponent{Id: "a"}
+		b = &treetest.StaticComponent{Id: "b"}
+		c = &treetest.StaticComponent{Id: "c"}
+
+		root.Children = []Component{a, b}
+		b.Children = []Component{c}
+
+		rootNode = NewNode(root, &rec)
+		rec.Clear()
+	})
+
+	It("should be given to no Node at first", func() {
+		Expect(rootNode.Focused()).To(BeNil())
+		Expect(visitedFrom(rootNode)).To(Equal([]string{"root"}))
+	})
+
+	When("requested through the StateController", func() {
+		BeforeEach(func() {
+			c.MountCalls[0].StateController.(Focuser).Focus()
+		})
+
+		It("should be given to its Node", func() {
+			Expect(rootNode.Focused()).To(BeIdenticalTo(nodeOf(c)))
+			Expect(nodeOf(c).HasFocus()).To(BeTrue())
+			Expect(nodeOf(a).HasFocus()).To(BeFalse())
+		})
+
+		It("should update the Nodes losing and gaining it", func() {
+			Expect(rec).To(treetest.HaveMappedInOrder("root/1/0"))
+
+			rec.Clear()
+			nodeOf(a).Focus()
+			Expect(rec).To(treetest.HaveMappedInOrder("root/1/0", "root/0"))
+		})
+
+		It("should bubble from it to the root", func() {
+			Expect(visitedFrom(rootNode)).To(Equal([]string{"root/1/0", "root/1", "root"}))
+		})
+
+		It("should stop bubbling once handled", func() {
+			var visited []*Node
+			Expect(rootNode.Bubble(func(n *Node) bool {
+				visited = append(visited, n)
+				return n == nodeOf(b)
+			})).To(BeTrue())
+
+			Expect(visited).To(Equal([]*Node{nodeOf(c), nodeOf(b)}))
+		})
+
+		It("should be lost when the Node is closed", func() {
+			b.Children = []Component{nil}
+			Expect(b.ForceUpdate()).To(Succeed())
+			Expect(rootNode.Focused()).To(BeNil())
+		})
+	})
+
+	Describe("MoveFocus", func() {
+		It("should move in Walk order, wrapping around", func() {
+			var order []*Node
+			for i := 0; i < 4; i++ {
+				order = append(order, rootNode.MoveFocus(1, notRoot))
+			}
+
+			Expect(order).To(Equal([]*Node{nodeOf(a), nodeOf(b), nodeOf(c), nodeOf(a)}))
+			Expect(rootNode.MoveFocus(-1, notRoot)).To(BeIdenticalTo(nodeOf(c)))
+		})
+
+		It("should start at the start forwards", func() {
+			Expect(rootNode.MoveFocus(1, notRoot)).To(BeIdenticalTo(nodeOf(a)))
+		})
+
+		It("should start at the end backwards", func() {
+			Expect(rootNode.MoveFocus(-1, notRoot)).To(BeIdenticalTo(nodeOf(c)))
+		})
+
+		It("should do nothing if nothing is focusable", func() {
+			Expect(rootNode.MoveFocus(1, func(*Node) bool { return false })).To(BeNil())
+			Expect(rootNode.Focused()).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,274 @@
+package tree_test
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+// a fuzzed Component is a StaticComponent
+// which may or may not always want to update.
+type fuzzed struct {
+	*treetest.StaticComponent
+	eager bool
+}
+
+func (f fuzzed) ShouldUpdate(old Component) (bool, error) {
+	f.StaticComponent.ShouldUpdate(old)
+	return f.eager, nil
+}
+
+const (
+	fuzzMaxDepth    = 4
+	fuzzMaxChildren = 3
+	fuzzMaxSteps    = 200
+)
+
+// FuzzTree builds a random tree, then randomly replaces, removes,
+// re-adds and updates its Components, checking after each step that:
+//
+//   - every Node is mounted once, and closed at most once
+//   - every mounted Node is either in the tree or closed
+//   - the Recorder has unmapped exactly what was closed
+//   - no Component is rendered twice in one update
+//
+// Replacing a Component may make its new Render return a different
+// number of children than its Node has; the resulting errors are
+// expected, and must leave the tree as it was.
+func FuzzTree(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{3, 1, 0, 2, 1, 1, 0, 0, 2, 3, 2, 1, 0, 1})
+	f.Add(bytes.Repeat([]byte{1, 2, 3, 0, 1, 7}, 30))
+	f.Add(bytes.Repeat([]byte{2, 0, 1, 1, 2, 2, 5, 3}, 30))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		h := &harness{t: t, data: data}
+		h.run()
+	})
+}
+
+type harness struct {
+	t    *testing.T
+	data []byte
+
+	rec  treetest.Recorder
+	root fuzzed
+	node *Node
+	all  []fuzzed
+}
+
+// intn consumes a choice in [0, n)
+// from the fuzzed data.
+func (h *harness) intn(n int) int {
+	if len(h.data) == 0 || n <= 1 {
+		return 0
+	}
+
+	b := h.data[0]
+	h.data = h.data[1:]
+	return int(b) % n
+}
+
+func (h *harness) newComponent(depth int) (c fuzzed) {
+	c = fuzzed{
+		StaticComponent: &treetest.StaticComponent{Id: strconv.Itoa(len(h.all))},
+		eager:           h.intn(2) == 0,
+	}
+
+	h.all = append(h.all, c)
+
+	if depth >= fuzzMaxDepth {
+		return
+	}
+
+	c.Children = make([]Component, h.intn(fuzzMaxChildren+1))
+	for i := range c.Children {
+		if h.intn(4) > 0 {
+			c.Children[i] = h.newComponent(depth + 1)
+		}
+	}
+
+	return
+}
+
+func (h *harness) run() {
+	h.root = h.newComponent(0)
+	h.root.Children = append(h.root.Children, nil)
+	h.node = NewNode(h.root, &h.rec)
+	h.check(nil)
+
+	for step := 0; step < fuzzMaxSteps && len(h.data) > 0; step++ {
+		h.step()
+	}
+
+	// closing everything should balance every
+	// mount with a close and an unmap
+	for i := range h.root.Children {
+		h.root.Children[i] = nil
+	}
+
+	h.update(h.node)
+
+	if mounted, closes := h.nodes(); len(mounted)-closes != 1 {
+		h.t.Fatalf("%d Nodes mounted and %d closed after removing all but the root", len(mounted), closes)
+	}
+}
+
+func (h *harness) step() {
+	live := h.live()
+	n := live[h.intn(len(live))]
+	target := n.Component.(fuzzed)
+
+	if n := len(target.Children); n > 0 {
+		i := h.intn(n)
+
+		switch h.intn(4) {
+		case 0:
+			h.t.Logf("replacing child %d of %s", i, target.Id)
+			target.Children[i] = h.newComponent(fuzzMaxDepth - 1)
+		case 1:
+			h.t.Logf("removing child %d of %s", i, target.Id)
+			target.Children[i] = nil
+		case 2:
+			if c, ok := h.detached(); ok {
+				h.t.Logf("re-adding %s as child %d of %s", c.Id, i, target.Id)
+				target.Children[i] = c
+			}
+		}
+	}
+
+	h.t.Logf("updating %s", target.Id)
+	h.update(n)
+}
+
+// update updates n, checking the invariants.
+func (h *harness) update(n *Node) {
+	before := make(map[*treetest.StaticComponent]int, len(h.all))
+	for _, c := range h.all {
+		before[c.StaticComponent] = len(c.RenderCalls)
+	}
+
+	n.Update()
+	h.check(before)
+}
+
+// live returns the Nodes in the tree.
+func (h *harness) live() (live []*Node) {
+	h.node.Walk(func(n *Node) error {
+		live = append(live, n)
+		return nil
+	})
+
+	return
+}
+
+// detached returns a Component which is not in the tree, and
+// which neither it, nor any of its descendants, would be rendered
+// by a Component in the tree.
+func (h *harness) detached() (c fuzzed, ok bool) {
+	used := make(map[*treetest.StaticComponent]bool)
+
+	var use func(c fuzzed)
+	use = func(c fuzzed) {
+		used[c.StaticComponent] = true
+
+		for _, child := range c.Children {
+			if child != nil {
+				use(child.(fuzzed))
+			}
+		}
+	}
+
+	for _, n := range h.live() {
+		use(n.Component.(fuzzed))
+	}
+
+	var free func(c fuzzed) bool
+	free = func(c fuzzed) bool {
+		if used[c.StaticComponent] {
+			return false
+		}
+
+		for _, child := range c.Children {
+			if child != nil && !free(child.(fuzzed)) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	var candidates []fuzzed
+	for _, c := range h.all {
+		if free(c) {
+			candidates = append(candidates, c)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return
+	}
+
+	return candidates[h.intn(len(candidates))], true
+}
+
+// nodes returns the Nodes that have been mounted, and
+// the number of times Components were closed.
+//
+// Since a Component replaced by another in the same place keeps its
+// Node, the Component closed may not be the one that was mounted, so
+// Nodes are counted rather than Components.
+func (h *harness) nodes() (mounted []*Node, closes int) {
+	seen := make(map[*Node]bool)
+
+	for _, c := range h.all {
+		closes += len(c.CloseCalls)
+
+		for _, m := range c.MountCalls {
+			n := m.StateController.(*Node)
+			if seen[n] {
+				h.t.Fatalf("%s mounted twice", n.Path())
+			}
+
+			seen[n] = true
+			mounted = append(mounted, n)
+		}
+	}
+
+	if unmaps := len(h.rec.ClosedComponents); unmaps != closes {
+		h.t.Fatalf("%d unmaps, but %d closes", unmaps, closes)
+	}
+
+	return
+}
+
+func (h *harness) check(renders map[*treetest.StaticComponent]int) {
+	for _, c := range h.all {
+		if n := len(c.RenderCalls) - renders[c.StaticComponent]; renders != nil && n > 1 {
+			h.t.Fatalf("%s rendered %d times in one update", c.Id, n)
+		}
+	}
+
+	// closed Nodes have no Component; every
+	// other mounted Node should be in the tree,
+	// and every closed Node closed only once.
+	mounted, closes := h.nodes()
+
+	open := 0
+	for _, n := range mounted {
+		if n.Component != nil {
+			open++
+		}
+	}
+
+	if closed := len(mounted) - open; closes != closed {
+		h.t.Fatalf("%d Nodes closed, but Close called %d times", closed, closes)
+	}
+
+	if live := len(h.live()); open != live {
+		h.t.Fatalf("%d Nodes open, but %d in the tree", open, live)
+	}
+}
@@ -0,0 +1,56 @@
+package tree
+
+// An Identified Component has an Identity which is unique across
+// its whole tree.
+//
+// Normally, a Component removed from one place in the tree and added
+// in another is closed, and mounted afresh, losing any state held by
+// its Node and its descendants. If an Identified Component is removed
+// from one place and added in another within the same update, its Node
+// moves with it instead, keeping its children, and it is neither closed
+// nor mounted, only asked whether it should update.
+//
+// A Component replacing one with a different Identity (or none) in the
+// same place is always mounted afresh.
+type Identified interface {
+	Component
+	Identity() string
+}
+
+// identityOf returns the Identity
+// of c, if it has one.
+func identityOf(c Component) string {
+	if i, ok := c.(Identified); ok {
+		return i.Identity()
+	}
+
+	return ""
+}
+
+// register records this Node as the
+// Node of its Component's Identity.
+func (n *Node) register() {
+	id := identityOf(n.Component)
+	if id == "" {
+		return
+	}
+
+	root := n.Root()
+	if root.identities == nil {
+		root.identities = make(map[string]*Node)
+	}
+
+	root.identities[id] = n
+}
+
+// unregister undoes register.
+func (n *Node) unregister() {
+	id := identityOf(n.Component)
+	if id == "" {
+		return
+	}
+
+	if root := n.Root(); root.identities[id] == n {
+		delete(root.identities, id)
+	}
+}
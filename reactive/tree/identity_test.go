@@ -0,0 +1,140 @@
+package tree_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/event"
+	"zemn.me/reactive/tree/treetest"
+)
+
+// identifiedComponent is a StaticComponent with an Identity.
+type identifiedComponent struct {
+	*treetest.StaticComponent
+	id string
+}
+
+func (i identifiedComponent) Identity() string { return i.id }
+
+var _ = Describe("Identified Components", func() {
+	var (
+		rec               treetest.Recorder
+		root, left, right *treetest.StaticComponent
+		panel, panelChild *treetest.StaticComponent
+		leftC, rightC     Component
+		panelC            Component
+		rootNode          *Node
+	)
+
+	BeforeEach(func() {
+		rec.Clear()
+		root = &treetest.StaticComponent{Id: "root"}
+		left = &treetest.StaticComponent{Id: "left"}
+		right = &treetest.StaticComponent{Id: "right"}
+		panel = &treetest.StaticComponent{Id: "panel"}
+		panelChild = &treetest.StaticComponent{Id: "panelChild"}
+
+		leftC, rightC = eagerComponent{StaticComponent: left}, eagerComponent{StaticComponent: right}
+		panelC = identifiedComponent{panel, "panel"}
+
+		root.Children = []Component{leftC, rightC}
+		left.Children = []Component{panelC}
+		right.Children = []Component{nil}
+		panel.Children = []Component{panelChild}
+
+		rootNode = NewNode(root, &rec)
+		rec.Clear()
+	})
+
+	When("moved to a new parent in one update", func() {
+		BeforeEach(func() {
+			left.Children = []Component{nil}
+			right.Children = []Component{panelC}
+			root.ForceUpdate()
+		})
+
+		It("should not be closed or mounted again", func() {
+			Expect(rec.Errors).To(HaveLen(0))
+			Expect(panel.MountCalls).To(HaveLen(1))
+			Expect(panel.CloseCalls).To(HaveLen(0))
+			Expect(rec.ClosedComponents).To(HaveLen(0))
+		})
+
+		It("should keep its children", func() {
+			Expect(panelChild.MountCalls).To(HaveLen(1))
+			Expect(panelChild.CloseCalls).To(HaveLen(0))
+			Expect(rootNode.Find(Named(panelChild.Name())).Path().String()).To(Equal("root/1/0/0"))
+		})
+
+		It("should be at its new place in the tree", func() {
+			n := rootNode.Find(Named(panel.Name()))
+			Expect(n.Path().String()).To(Equal("root/1/0"))
+			Expect(rootNode.Lookup(ParsePath("root/0/0"))).To(BeNil())
+		})
+
+		It("should tell a PathMapper its new Path, and its children's", func() {
+			Expect(rec.Paths(event.Map)).To(ContainElement("root/1/0"))
+			Expect(rec.Paths(event.Map)).To(ContainElement("root/1/0/0"))
+		})
+
+		It("should still be able to update itself", func() {
+			rec.Clear()
+			Expect(panel.ForceUpdate()).To(Succeed())
+			Expect(rec.Components).To(ConsistOf(panelC))
+		})
+	})
+
+	When("its old parent is removed in the same update", func() {
+		BeforeEach(func() {
+			root.Children = []Component{nil, rightC}
+			right.Children = []Component{panelC}
+			root.ForceUpdate()
+		})
+
+		It("should close the old parent, but not move with it", func() {
+			Expect(left.CloseCalls).To(HaveLen(1))
+			Expect(panel.CloseCalls).To(HaveLen(0))
+			Expect(panelChild.CloseCalls).To(HaveLen(0))
+			Expect(rec.ClosedComponents).To(ConsistOf(leftC))
+		})
+	})
+
+	When("added to a new parent without being removed from the old one", func() {
+		BeforeEach(func() {
+			right.Children = []Component{panelC}
+			root.ForceUpdate()
+		})
+
+		It("should report an error", func() {
+			Expect(rec.Errors).To(HaveLen(1))
+			Expect(rec.Errors[0].Err.Error()).To(ContainSubstring("root/0/0"))
+		})
+
+		It("should not commit anything", func() {
+			Expect(rec.Components).To(HaveLen(0))
+			Expect(rootNode.Lookup(ParsePath("root/0/0"))).ToNot(BeNil())
+			Expect(rootNode.Lookup(ParsePath("root/1/0"))).To(BeNil())
+		})
+	})
+
+	When("removed and added in separate updates", func() {
+		BeforeEach(func() {
+			left.Children = []Component{nil}
+			root.ForceUpdate()
+
+			right.Children = []Component{panelC}
+			root.ForceUpdate()
+		})
+
+		It("should be closed, and mounted again", func() {
+			Expect(panel.CloseCalls).To(HaveLen(1))
+			Expect(panel.MountCalls).To(HaveLen(2))
+		})
+
+		It("should close its children too", func() {
+			Expect(panelChild.CloseCalls).To(HaveLen(1))
+			Expect(panelChild.MountCalls).To(HaveLen(2))
+		})
+	})
+})
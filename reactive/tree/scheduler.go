@@ -0,0 +1,330 @@
+package tree
+
+import (
+	"context"
+	"sync"
+
+	"zemn.me/debug"
+)
+
+// A Priority orders pending updates. Updates of higher
+// Priority are performed first, and interrupt the rendering
+// of updates of lower Priority.
+type Priority int
+
+const (
+	// BackgroundPriority is for updates driven
+	// by data, for example a timer or a poller.
+	BackgroundPriority Priority = iota
+
+	// InputPriority is for updates driven by the
+	// user, for example key presses or resizes.
+	InputPriority
+)
+
+func (p Priority) String() string {
+	switch p {
+	case BackgroundPriority:
+		return "background"
+	case InputPriority:
+		return "input"
+	}
+
+	return "unknown"
+}
+
+// A Prioritizer is a StateController which can request updates
+// of a Priority other than BackgroundPriority. The StateController
+// a Node passes to Mount is a Prioritizer.
+type Prioritizer interface {
+	StateController
+
+	// UpdatePriority requests a re-render at Priority p.
+	UpdatePriority(p Priority)
+}
+
+var _ Prioritizer = &Node{}
+
+// UpdatePriority requests a re-render through s at Priority p,
+// if s is a Prioritizer, and otherwise with s.Update().
+func UpdatePriority(s StateController, p Priority) {
+	if pr, ok := s.(Prioritizer); ok {
+		pr.UpdatePriority(p)
+		return
+	}
+
+	s.Update()
+}
+
+type request struct {
+	node     *Node
+	priority Priority
+
+	// the Paths of the Nodes whose updates
+	// led to this one; see DefaultCascadeLimit.
+	cascade []Path
+}
+
+// a loop is an update which was not
+// queued because of an update loop.
+type loop struct {
+	node *Node
+	err  CascadeError
+}
+
+// A Scheduler queues updates to the Nodes of a tree and
+// performs them in order of Priority.
+//
+// If an update of higher Priority is requested while the Scheduler
+// is rendering, the render is abandoned between Nodes, the
+// higher Priority update is performed, and the abandoned update is
+// then restarted from the beginning.
+//
+// All rendering and mapping happens on the goroutine calling
+// Step, Flush or Run; updates may be requested from any goroutine.
+// Other goroutines may also pass functions to Do to be called there.
+type Scheduler struct {
+	// CascadeLimit is the most updates which may cascade
+	// from a single update; if zero, DefaultCascadeLimit.
+	// A limit set on a tree with SetCascadeLimit takes
+	// precedence.
+	CascadeLimit int
+
+	mu      sync.Mutex
+	pending []request
+	wake    chan struct{}
+
+	// the functions passed to Do, to be
+	// called before the next update
+	tasks []func()
+
+	// the request being performed,
+	// and the loops it caused.
+	current *request
+	loops   []loop
+}
+
+// NewScheduler returns a new Scheduler with nothing to do.
+func NewScheduler() *Scheduler {
+	return &Scheduler{wake: make(chan struct{}, 1)}
+}
+
+// NewNode constructs a new state tree rooted at the Component c,
+// like the package-level NewNode. Unlike NewNode, the first
+// render is queued on the Scheduler rather than performed
+// immediately.
+func (s *Scheduler) NewNode(c Component, m Mapper) (n *Node) {
+	n = new(Node)
+	n.Component = c
+	n.Mapper = m
+	n.scheduler = s
+	n.focused = new(focus)
+
+	n.register()
+	n.Mount(n)
+	n.Update()
+	return
+}
+
+// Do queues f to be called on the goroutine calling Step, Flush or
+// Run, before the next update is performed, so that f may read and
+// change the trees of the Scheduler without racing with an update.
+// Functions are called in the order they were passed to Do.
+func (s *Scheduler) Do(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.wakeUp()
+
+	s.tasks = append(s.tasks, f)
+}
+
+// wakeUp wakes Run, if it is waiting.
+func (s *Scheduler) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) schedule(n *Node, p Priority) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.wakeUp()
+
+	for i := range s.pending {
+		if s.pending[i].node != n {
+			continue
+		}
+
+		if p > s.pending[i].priority {
+			s.pending[i].priority = p
+		}
+
+		return
+	}
+
+	r := request{node: n, priority: p}
+
+	// only updates requested while an update is
+	// being performed cascade from it.
+	if s.current != nil {
+		r.cascade = append(append([]Path(nil), s.current.cascade...), n.Path())
+	}
+
+	limit := s.CascadeLimit
+	if limit == 0 {
+		limit = DefaultCascadeLimit
+	}
+	limit = n.Root().cascadeLimit(limit)
+
+	if len(r.cascade) > limit {
+		s.loops = append(s.loops, loop{n, CascadeError{Cascade: r.cascade}})
+		return
+	}
+
+	s.pending = append(s.pending, r)
+}
+
+// requeue puts an interrupted request back in the queue.
+func (s *Scheduler) requeue(r request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.pending {
+		if s.pending[i].node == r.node {
+			if r.priority > s.pending[i].priority {
+				s.pending[i].priority = r.priority
+			}
+
+			return
+		}
+	}
+
+	s.pending = append(s.pending, r)
+}
+
+// next removes and returns the oldest pending request
+// of the highest Priority.
+func (s *Scheduler) next() (r request, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 {
+		return
+	}
+
+	best := 0
+	for i := range s.pending {
+		if s.pending[i].priority > s.pending[best].priority {
+			best = i
+		}
+	}
+
+	r, ok = s.pending[best], true
+	s.pending = append(s.pending[:best], s.pending[best+1:]...)
+	s.current = &r
+	return
+}
+
+// done finishes the current request, returning
+// the loops it caused.
+func (s *Scheduler) done() (loops []loop) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loops, s.loops = s.loops, nil
+	s.current = nil
+	return
+}
+
+// preempted reports whether an update of
+// Priority higher than p is pending.
+func (s *Scheduler) preempted(p Priority) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.pending {
+		if r.priority > p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Pending returns the number of queued updates.
+func (s *Scheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.pending)
+}
+
+// Step calls the functions passed to Do since the last Step, if
+// any, and otherwise performs the pending update of the highest
+// Priority, reporting whether there was anything to do.
+//
+// If an update of higher Priority is requested while rendering,
+// the render is abandoned and queued again.
+//
+// Updates requested while performing an update are queued,
+// unless they cascade from it beyond the CascadeLimit, in which
+// case a CascadeError is passed to the Mapper instead.
+func (s *Scheduler) Step() bool {
+	s.mu.Lock()
+	tasks := s.tasks
+	s.tasks = nil
+	s.mu.Unlock()
+
+	if len(tasks) > 0 {
+		for _, f := range tasks {
+			f()
+		}
+
+		return true
+	}
+
+	r, ok := s.next()
+	if !ok {
+		return false
+	}
+
+	// if the update panics, later updates
+	// must not cascade from it.
+	defer s.done()
+
+	err := r.node.update(func() bool { return s.preempted(r.priority) })
+
+	for _, l := range s.done() {
+		l.node.report(l.err)
+	}
+
+	if err == errInterrupted {
+		debug.Log("%s update at %s priority interrupted", r.node.Path(), r.priority)
+		s.requeue(r)
+		return true
+	}
+
+	r.node.report(err)
+	return true
+}
+
+// Flush performs pending updates until there are none left.
+func (s *Scheduler) Flush() {
+	for s.Step() {
+	}
+}
+
+// Run performs updates as they are requested,
+// until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		s.Flush()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.wake:
+		}
+	}
+}
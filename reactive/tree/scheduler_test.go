@@ -0,0 +1,161 @@
+package tree_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+// eagerComponent is a StaticComponent which always
+// wants to update, and can run a hook when rendered.
+type eagerComponent struct {
+	*treetest.StaticComponent
+	onRender func()
+}
+
+func (eagerComponent) ShouldUpdate(Component) (bool, error) { return true, nil }
+func (e eagerComponent) Render() ([]Component, error) {
+	if e.onRender != nil {
+		e.onRender()
+	}
+
+	return e.StaticComponent.Render()
+}
+
+var _ = Describe("Scheduler", func() {
+	var (
+		s                *Scheduler
+		rec              treetest.Recorder
+		root, a, b       *treetest.StaticComponent
+		aHook            func()
+		rootNode         *Node
+		mappedComponents func() []string
+	)
+
+	mappedComponents = func() (ids []string) {
+		for _, c := range rec.Components {
+			switch v := c.(type) {
+			case *treetest.StaticComponent:
+				ids = append(ids, v.Id)
+			case eagerComponent:
+				ids = append(ids, v.Id)
+			}
+		}
+		return
+	}
+
+	BeforeEach(func() {
+		rec.Clear()
+		aHook = nil
+		root = &treetest.StaticComponent{Id: "root"}
+		a = &treetest.StaticComponent{Id: "a"}
+		b = &treetest.StaticComponent{Id: "b"}
+
+		root.Children = []Component{
+			eagerComponent{a, func() {
+				if aHook != nil {
+					aHook()
+				}
+			}},
+			eagerComponent{StaticComponent: b},
+		}
+
+		s = NewScheduler()
+		rootNode = s.NewNode(root, &rec)
+	})
+
+	It("should not render until asked to", func() {
+		Expect(rootNode.Children).To(HaveLen(0))
+		Expect(rec.Components).To(HaveLen(0))
+		Expect(s.Pending()).To(Equal(1))
+	})
+
+	When("flushed", func() {
+		BeforeEach(func() { s.Flush() })
+
+		It("should render the whole tree", func() {
+			Expect(mappedComponents()).To(Equal([]string{"root", "a", "b"}))
+			Expect(s.Pending()).To(Equal(0))
+		})
+
+		It("should perform higher priority updates first", func() {
+			rec.Clear()
+			b.ForceUpdate()
+			a.MountCalls[0].StateController.(Prioritizer).UpdatePriority(InputPriority)
+
+			Expect(s.Step()).To(BeTrue())
+			Expect(mappedComponents()).To(Equal([]string{"a"}))
+
+			Expect(s.Step()).To(BeTrue())
+			Expect(mappedComponents()).To(Equal([]string{"a", "b"}))
+
+			Expect(s.Step()).To(BeFalse())
+		})
+
+		It("should call functions passed to Do before the next update, in order", func() {
+			var called []string
+			b.ForceUpdate()
+			rootNode.Do(func() {
+				called = append(called, "first")
+				Expect(rec.Components).To(HaveLen(3))
+			})
+			s.Do(func() { called = append(called, "second") })
+			Expect(called).To(BeEmpty())
+
+			Expect(s.Step()).To(BeTrue())
+			Expect(called).To(Equal([]string{"first", "second"}))
+			Expect(s.Pending()).To(Equal(1))
+		})
+
+		It("should merge repeated requests for the same Node", func() {
+			b.ForceUpdate()
+			b.ForceUpdate()
+			Expect(s.Pending()).To(Equal(1))
+		})
+
+		When("a higher priority update arrives mid-render", func() {
+			BeforeEach(func() {
+				rec.Clear()
+				aHook = func() {
+					aHook = nil
+					b.MountCalls[0].StateController.(Prioritizer).UpdatePriority(InputPriority)
+				}
+
+				root.ForceUpdate()
+				Expect(s.Step()).To(BeTrue())
+			})
+
+			It("should not commit any of the interrupted render", func() {
+				Expect(rec.Components).To(HaveLen(0))
+			})
+
+			It("should keep both updates pending", func() {
+				Expect(s.Pending()).To(Equal(2))
+			})
+
+			It("should perform the higher priority update, then restart", func() {
+				Expect(s.Step()).To(BeTrue())
+				Expect(mappedComponents()).To(Equal([]string{"b"}))
+
+				Expect(s.Step()).To(BeTrue())
+				Expect(mappedComponents()).To(Equal([]string{"b", "root", "a", "b"}))
+			})
+		})
+	})
+})
+
+// an updateCounter is a StateController
+// which is not a Prioritizer.
+type updateCounter int
+
+func (u *updateCounter) Update() { *u++ }
+
+var _ = Describe("UpdatePriority", func() {
+	It("should fall back to Update for StateControllers which are not Prioritizers", func() {
+		var u updateCounter
+		UpdatePriority(&u, InputPriority)
+		Expect(int(u)).To(Equal(1))
+	})
+})
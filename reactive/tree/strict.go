@@ -0,0 +1,10 @@
+package tree
+
+// Strict, in builds with the 'debug' tag, makes the tree call
+// Render and ShouldUpdate twice and compare the results, asserting
+// via zemn.me/debug that Components are pure: that they render the
+// same way each time, and that ShouldUpdate modifies neither the
+// new nor the old Component (for example, by writing to a canvas).
+//
+// Without the 'debug' tag, Strict does nothing.
+var Strict = false
@@ -0,0 +1,139 @@
+//+build debug
+
+package tree
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"zemn.me/debug"
+)
+
+// checkRender renders c again and asserts
+// it renders the same way.
+func checkRender(c Component, children []Component, err error) {
+	if !Strict {
+		return
+	}
+
+	again, againErr := c.Render()
+
+	a, b := fingerprint(children), fingerprint(again)
+
+	debug.Assert(
+		a == b && (err == nil) == (againErr == nil),
+		"strict: %s rendered differently when rendered twice:\n"+
+			"first: %s (error: %v)\nsecond: %s (error: %v)",
+		c.Name(), a, err, b, againErr,
+	)
+}
+
+// askShouldUpdate calls newChild.ShouldUpdate(oldChild), and, if
+// Strict, asserts that doing so changes neither Component and
+// gives the same result when called again.
+func askShouldUpdate(newChild, oldChild Component) (should bool, err error) {
+	if !Strict {
+		return newChild.ShouldUpdate(oldChild)
+	}
+
+	before := fingerprint(newChild) + fingerprint(oldChild)
+
+	should, err = newChild.ShouldUpdate(oldChild)
+
+	after := fingerprint(newChild) + fingerprint(oldChild)
+
+	debug.Assert(
+		before == after,
+		"strict: %s modified state in ShouldUpdate:\nbefore: %s\nafter: %s",
+		newChild.Name(), before, after,
+	)
+
+	again, againErr := newChild.ShouldUpdate(oldChild)
+
+	debug.Assert(
+		should == again && (err == nil) == (againErr == nil),
+		"strict: %s.ShouldUpdate gave %v (error: %v), then %v (error: %v)",
+		newChild.Name(), should, err, again, againErr,
+	)
+
+	return
+}
+
+// fingerprint returns a string describing everything
+// reachable from v, for comparison.
+func fingerprint(v interface{}) string {
+	var b strings.Builder
+	writeFingerprint(&b, reflect.ValueOf(v), make(map[uintptr]bool))
+	return b.String()
+}
+
+func writeFingerprint(b *strings.Builder, v reflect.Value, seen map[uintptr]bool) {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+
+		if seen[v.Pointer()] {
+			b.WriteString("<cycle>")
+			return
+		}
+
+		seen[v.Pointer()] = true
+		defer delete(seen, v.Pointer())
+
+		b.WriteString("&")
+		writeFingerprint(b, v.Elem(), seen)
+
+	case reflect.Interface:
+		writeFingerprint(b, v.Elem(), seen)
+
+	case reflect.Struct:
+		fmt.Fprintf(b, "%s{", v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			fmt.Fprintf(b, "%s:", v.Type().Field(i).Name)
+			writeFingerprint(b, v.Field(i), seen)
+			b.WriteString(" ")
+		}
+		b.WriteString("}")
+
+	case reflect.Slice, reflect.Array:
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			writeFingerprint(b, v.Index(i), seen)
+			b.WriteString(" ")
+		}
+		b.WriteString("]")
+
+	case reflect.Map:
+		// map order is random, so only
+		// the size is compared.
+		fmt.Fprintf(b, "map[len %d]", v.Len())
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		// same code, or same channel
+		fmt.Fprintf(b, "%s(%x)", v.Type(), v.Pointer())
+
+	case reflect.Bool:
+		fmt.Fprint(b, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fmt.Fprint(b, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		fmt.Fprint(b, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		fmt.Fprint(b, v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		fmt.Fprint(b, v.Complex())
+	case reflect.String:
+		fmt.Fprintf(b, "%q", v.String())
+	default:
+		fmt.Fprintf(b, "<%s>", v.Kind())
+	}
+}
@@ -0,0 +1,12 @@
+//+build !debug
+
+package tree
+
+// Strict mode is only available in debug builds;
+// see ./strict_debug.go.
+
+func checkRender(Component, []Component, error) {}
+
+func askShouldUpdate(newChild, oldChild Component) (bool, error) {
+	return newChild.ShouldUpdate(oldChild)
+}
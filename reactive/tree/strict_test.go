@@ -0,0 +1,77 @@
+//+build debug
+
+package tree_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+// canvasWriter writes to its canvas in ShouldUpdate.
+type canvasWriter struct {
+	Canvas []rune
+}
+
+func (canvasWriter) Name() string                 { return "canvasWriter" }
+func (canvasWriter) Mount(StateController)        {}
+func (canvasWriter) Close()                       {}
+func (canvasWriter) Render() ([]Component, error) { return nil, nil }
+func (c canvasWriter) ShouldUpdate(Component) (bool, error) {
+	c.Canvas[0] = 'x'
+	return true, nil
+}
+
+// flipFlop renders a different child each time.
+type flipFlop struct{ renders *int }
+
+func (flipFlop) Name() string                         { return "flipFlop" }
+func (flipFlop) Mount(StateController)                {}
+func (flipFlop) Close()                               {}
+func (flipFlop) ShouldUpdate(Component) (bool, error) { return true, nil }
+func (f flipFlop) Render() ([]Component, error) {
+	*f.renders++
+	if *f.renders%2 == 0 {
+		return []Component{nil}, nil
+	}
+	return []Component{canvasWriter{}}, nil
+}
+
+var _ = Describe("Strict", func() {
+	var (
+		rec  treetest.Recorder
+		root *treetest.StaticComponent
+	)
+
+	BeforeEach(func() {
+		Strict = true
+		rec.Clear()
+		root = &treetest.StaticComponent{Id: "root"}
+	})
+
+	AfterEach(func() { Strict = false })
+
+	It("should render each Component twice", func() {
+		NewNode(root, &rec)
+		Expect(root.RenderCalls).To(HaveLen(2))
+	})
+
+	It("should allow pure Components", func() {
+		root.Children = []Component{&treetest.StaticComponent{Id: "child"}}
+		Expect(func() { NewNode(root, &rec) }).ToNot(Panic())
+	})
+
+	It("should catch Components which render differently each time", func() {
+		root.Children = []Component{flipFlop{new(int)}}
+		Expect(func() { NewNode(root, &rec) }).To(Panic())
+	})
+
+	It("should catch Components which write to their canvas in ShouldUpdate", func() {
+		root.Children = []Component{canvasWriter{Canvas: []rune{' '}}}
+		NewNode(root, &rec)
+
+		Expect(func() { root.ForceUpdate() }).To(Panic())
+	})
+})
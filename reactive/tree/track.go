@@ -0,0 +1,68 @@
+package tree
+
+// A Tracker is told about every call to Component.Render,
+// so that it can find out what state a Component reads while
+// rendering, and update the Component when that state changes.
+//
+// zemn.me/reactive uses a Tracker to subscribe Components to
+// the Signals they read.
+type Tracker interface {
+	// Render calls render, which renders c, returning
+	// what render returns and what c depended on, if anything.
+	Render(c Component, render func() ([]Component, error)) ([]Component, Dependency, error)
+}
+
+// A Dependency is some state a Component read while rendering.
+type Dependency interface {
+	// Watch is called when the render which produced the
+	// Dependency is committed. Until cancel is called, Watch
+	// should call s.Update() each time the Dependency changes.
+	Watch(s StateController) (cancel func())
+}
+
+var trackers []Tracker
+
+// RegisterTracker adds t to the Trackers told about every
+// render of every tree. It is not safe to call RegisterTracker
+// while a tree is rendering; it should be called from init.
+func RegisterTracker(t Tracker) { trackers = append(trackers, t) }
+
+// renderTracked renders c, telling each registered
+// Tracker.
+func renderTracked(c Component) (children []Component, deps []Dependency, err error) {
+	render := c.Render
+
+	for _, t := range trackers {
+		t, next := t, render
+		render = func() (children []Component, err error) {
+			children, dep, err := t.Render(c, next)
+			if dep != nil {
+				deps = append(deps, dep)
+			}
+
+			return children, err
+		}
+	}
+
+	children, err = render()
+	return
+}
+
+// watch replaces what this Node watches with deps.
+func (n *Node) watch(deps []Dependency) {
+	n.unwatch()
+
+	for _, dep := range deps {
+		n.watching = append(n.watching, dep.Watch(n))
+	}
+}
+
+// unwatch stops watching the Dependencies of
+// the last committed render.
+func (n *Node) unwatch() {
+	for _, cancel := range n.watching {
+		cancel()
+	}
+
+	n.watching = nil
+}
@@ -56,16 +56,29 @@ state, we panic if a Component.Render() produces a different number of children.
 
 If a Component.Render() would cause a child to be removed, it instead returns
 a nil Component. When a nil Component is returned, the Component.Close() function
-of the Component that used to be in that slot is called to allow it to clean
-itself up.
+of the Component that used to be in that slot, and those of its descendants, are
+called to allow them to clean themselves up. An Identified Component may instead
+move elsewhere in the tree, keeping its state.
+
+An update happens in two phases. First the Node and its children are rendered,
+calling only Render() and ShouldUpdate(). Then the result is committed, calling
+Mount(), Close() and the Mapper. Nothing is committed until the whole subtree
+has rendered, so an update that fails or is abandoned part-way through never
+leaves the Mapper with half a frame.
+
+By default, Update() renders and commits immediately. A tree constructed by a
+Scheduler instead queues updates by Priority, and abandons a render between
+Nodes if an update of higher Priority is requested, restarting it later.
 
 
 */
 package tree
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"zemn.me/debug"
 )
@@ -88,17 +101,94 @@ type Mapper interface {
 	Error(c Component, err error)
 }
 
+// A Committer is a Mapper which wants to know when a
+// whole update has been mapped, for example to flush
+// a screen once per frame rather than once per Component.
+type Committer interface {
+	Mapper
+
+	// Commit is called after every Map and UnMap
+	// of an update has been made.
+	Commit()
+}
+
+// A Committed Component is told each time an update of it
+// has been committed. Rendering may be abandoned, so a Component
+// should act on what it rendered here, rather than in Render.
+type Committed interface {
+	Component
+
+	// Committed is called after the update has been
+	// mapped, and the Mapper told of the commit.
+	Committed()
+}
+
+// A successor is a Component which carries on the state of the
+// Component it replaces at a Node, such as the load of an Async.
+// succeed is called before ShouldUpdate, and may be called for a
+// render which is later abandoned.
+type successor interface {
+	Component
+	succeed(old Component)
+}
+
+// A PathMapper is a Mapper which wants to know where in the
+// tree each Component it is passed is. Its methods are called
+// in place of Map, UnMap and Error.
+type PathMapper interface {
+	Mapper
+
+	MapPath(p Path, c Component)
+	UnMapPath(p Path, c Component)
+
+	// ErrorPath is passed the Path of the Component
+	// that failed to update.
+	ErrorPath(p Path, c Component, err error)
+}
+
 // A Node represents a state tree. The Node ultimately
 // maintains state updates for a Component and determines if
 // its children should decide whether to update or not.
 type Node struct {
 	Component
-	Children []Node
+	Children []*Node
 	Mapper
 
 	// used to check if the number
 	// of children has changed after first render
 	previouslyRendered bool
+
+	// if set, updates to this Node are queued
+	// on the Scheduler instead of being performed
+	// immediately.
+	scheduler *Scheduler
+
+	// cancels watching the Dependencies
+	// of the last committed render.
+	watching []func()
+
+	// the Node this Node is a child of,
+	// and which child; nil for the root.
+	parent *Node
+	index  int
+
+	// for the root of a tree: its cascade limit, if set.
+	// For the root of a tree without a Scheduler: whether
+	// the tree is updating, the cascade of the update being
+	// performed, and the updates requested meanwhile.
+	updateMu sync.Mutex
+	limit    int
+	updating bool
+	cascade  []Path
+	deferred []deferral
+
+	// for the root of a tree: the Nodes
+	// of Identified Components, by Identity
+	identities map[string]*Node
+
+	// for the root of a tree: which Node has focus,
+	// made under updateMu if not by NewNode.
+	focused *focus
 }
 
 // NewNode constructs a new state tree rooted at the Component c,
@@ -108,71 +198,197 @@ func NewNode(c Component, m Mapper) (n *Node) {
 	n = new(Node)
 	n.Component = c
 	n.Mapper = m
+	n.focused = new(focus)
 
+	n.register()
+	n.Mount(n)
 	n.Update()
 	return
 }
 
-func (n Node) prepareChildren(nodes ...Node) (newChildren []Node) {
-	for i := range nodes {
-		nodes[i].Mapper = n.Mapper
-	}
+// Unmount closes and unmaps the Components of this Node and its
+// descendants, leaving them empty, so that updates requested
+// afterwards do nothing. It is called on the root of a tree that is
+// no longer wanted, and must not be called while the tree is updating.
+func (n *Node) Unmount() {
+	n.close(n.newPass(nil))
+}
 
-	return nodes
+// newChild returns a new, empty
+// i-th child of this Node.
+func (n *Node) newChild(i int) *Node {
+	return &Node{
+		Mapper:    n.Mapper,
+		scheduler: n.scheduler,
+		parent:    n,
+		index:     i,
+	}
 }
 
-// The update function re-renders the children of this Node,
-// and asks them if they need to update their children via Update().
+// A frame is a rendered, but not yet committed
+// update of a Node.
 //
-// Errors are handled by the Update() function, which passes them to the
-// Mapper.
-func (n *Node) update() (err error) {
-	debug.Log(" %s performing update ", n.Component.Name())
+// Rendering a Node calls only Render and ShouldUpdate; the
+// effects of the update (Map, UnMap, Mount and Close) are held
+// in the frame until the whole subtree has been rendered, so
+// that an abandoned render never leaves a partially updated
+// tree behind.
+type frame struct {
+	children []childFrame
+
+	// what the Component read while rendering
+	deps []Dependency
+}
 
-	// Tell the mapper this Component has updated.
-	n.Mapper.Map(n.Component)
+type childFrame struct {
+	Component
 
-	debug.Log("%s mapper updated", n.Component.Name())
+	mounted, unmounted bool
+
+	// if non-nil, the Node which is
+	// moving here from elsewhere in the tree.
+	moved *Node
+
+	// if non-nil, the child should update
+	// and this is its rendered frame.
+	*frame
+}
+
+// A pass is the state of rendering
+// and committing a single update.
+type pass struct {
+	// if non-nil, called between each child Node;
+	// if it returns true, rendering is abandoned.
+	interrupt func() bool
+
+	// the Nodes of the tree, by Identity
+	identities map[string]*Node
+
+	// Nodes moving to a new place in the tree, and
+	// the Component they are moving as
+	moved map[*Node]Component
+
+	// Nodes whose Components are being removed
+	removed map[*Node]bool
+
+	// the Path of the Node being rendered
+	at Path
+
+	// the Committed Components of the pass,
+	// to be told once it has been committed
+	committed []Committed
+
+	// the Nodes mapped in the pass
+	mapped map[*Node]bool
+}
+
+func (n *Node) newPass(interrupt func() bool) *pass {
+	return &pass{
+		at:         n.Path(),
+		interrupt:  interrupt,
+		identities: n.Root().identities,
+		moved:      make(map[*Node]Component),
+		removed:    make(map[*Node]bool),
+		mapped:     make(map[*Node]bool),
+	}
+}
+
+// check checks that every Node moved in the pass has
+// been removed from its old place in the tree.
+func (p *pass) check() error {
+	for m, c := range p.moved {
+		removed := false
+		for a := m; a != nil && !removed; a = a.parent {
+			removed = p.removed[a]
+		}
 
-	newChildren, err := n.Render()
+		if !removed {
+			return updateError{c, fmt.Errorf(
+				"%s is already at %s; an Identified Component"+
+					" may only move in the same update that"+
+					" removes it from its old place",
+				identityOf(c), m.Path(),
+			), m.Path()}
+		}
+	}
+
+	return nil
+}
+
+// errInterrupted is returned by Node.render when
+// rendering was abandoned because interrupt() returned true.
+var errInterrupted = errors.New("render interrupted")
+
+// An updateError is an error that occurred updating
+// a specific Component, at Path path.
+type updateError struct {
+	Component
+	err  error
+	path Path
+}
+
+// errorAt returns an updateError for Component c at the Path being
+// rendered, or its child with the given segment, if any.
+func (p *pass) errorAt(c Component, err error, segment ...string) updateError {
+	return updateError{c, err, append(append(Path{}, p.at...), segment...)}
+}
+
+func (u updateError) Error() string {
+	return fmt.Sprintf(
+		"Update error in Component %s: %s",
+		reflect.TypeOf(u.Component),
+		u.err,
+	)
+}
+
+// The render function renders the Component c as though it were
+// the new Component of this Node, and asks its children if they
+// need to update. It does not modify the Node.
+func (n *Node) render(c Component, p *pass) (f *frame, err error) {
+	debug.Log(" %s performing update ", c.Name())
+
+	newChildren, deps, err := renderTracked(c)
+	checkRender(c, newChildren, err)
 
 	if err != nil {
-		return
+		return nil, p.errorAt(c, err)
 	}
 
 	if n.previouslyRendered {
-		debug.Log("%s this is not the first time this component has rendered", reflect.TypeOf(n.Component))
+		debug.Log("%s this is not the first time this component has rendered", reflect.TypeOf(c))
 
 		if len(newChildren) != len(n.Children) {
-			return fmt.Errorf(
+			return nil, p.errorAt(c, fmt.Errorf(
 				"had %d Children and now has %d;"+
 					" the number of children a Component has is not"+
 					" allowed to change",
 
 				len(n.Children),
 				len(newChildren),
-			)
+			))
 		}
 	}
 
-	n.previouslyRendered = true
+	if err = checkKeys(newChildren); err != nil {
+		return nil, p.errorAt(c, err)
+	}
 
-	debug.Log("%s diffing %d children", n.Component.Name(), len(newChildren))
+	debug.Log("%s diffing %d children", c.Name(), len(newChildren))
 
-	if len(newChildren) > len(n.Children) {
-		debug.Log(
-			"%s making new Node.Children",
-			n.Component.Name(),
-		)
-		n.Children = n.prepareChildren(make([]Node, len(newChildren))...)
-	}
+	f = &frame{children: make([]childFrame, len(newChildren)), deps: deps}
 
 	for i := range newChildren {
-		newChild, oldChild := newChildren[i], n.Children[i].Component
+		oldNode := new(Node)
+		if i < len(n.Children) {
+			oldNode = n.Children[i]
+		}
+
+		newChild, oldChild := newChildren[i], oldNode.Component
 
 		shouldUpdate := false
 		mounted := false
 		unmounted := false
+		var moved *Node
 
 		switch {
 		// was nil, now defined, no need to ask if update is needed
@@ -182,15 +398,27 @@ func (n *Node) update() (err error) {
 
 			//unmounted = false
 
+		// both new and old were non-nil, but
+		// the new one has a different Identity or Key
+		case newChild != nil && oldChild != nil &&
+			(identityOf(newChild) != identityOf(oldChild) || keyOf(newChild) != keyOf(oldChild)):
+			shouldUpdate = true
+			mounted = true
+			unmounted = true
+
 		// both new and old were non-nil:
 		// delegate to new child as to whether
 		// update is needed
 		case newChild != nil && oldChild != nil:
 			debug.Log("[%s] ShouldUpdate?", newChild.Name())
 
-			shouldUpdate, err = newChild.ShouldUpdate(oldChild)
+			if s, ok := newChild.(successor); ok {
+				s.succeed(oldChild)
+			}
+
+			shouldUpdate, err = askShouldUpdate(newChild, oldChild)
 			if err != nil {
-				return
+				return nil, p.errorAt(newChild, err, segmentOf(newChild, i))
 			}
 
 			//mounted = false
@@ -213,75 +441,310 @@ func (n *Node) update() (err error) {
 			))
 		}
 
-		debug.Log(
-			"%s child %d was, now ",
-			n.Component.Name(),
-			i,
-		)
+		// a newly mounted Identified child may instead
+		// be moving from elsewhere in the tree
+		if id := identityOf(newChild); mounted && id != "" && p.identities[id] != nil {
+			moved, mounted = p.identities[id], false
+
+			if _, ok := p.moved[moved]; ok {
+				return nil, p.errorAt(newChild, fmt.Errorf(
+					"%s is rendered in more than one place", id,
+				), segmentOf(newChild, i))
+			}
+
+			p.moved[moved] = newChild
+
+			debug.Log("[%s] ShouldUpdate after moving from %s?", newChild.Name(), moved.Path())
+
+			if s, ok := newChild.(successor); ok {
+				s.succeed(moved.Component)
+			}
+
+			shouldUpdate, err = askShouldUpdate(newChild, moved.Component)
+			if err != nil {
+				return nil, p.errorAt(newChild, err, segmentOf(newChild, i))
+			}
+		}
+
+		if unmounted {
+			p.removed[oldNode] = true
+		}
 
 		debug.Log(
 			`%s child %d:
 	was unmounted: %v
 	was mounted: %v
+	was moved: %v
 	needs to be updated: %v`,
-			n.Component.Name(),
+			c.Name(),
 			i,
 			unmounted,
 			mounted,
+			moved != nil,
 			shouldUpdate,
 		)
 
 		debug.Assert(
-			!(unmounted == true && mounted == unmounted),
-			"cannot be both mounted and unmounted! "+
-				"mounted: %v, unmounted: %v",
-			mounted, unmounted,
+			!(mounted && moved != nil),
+			"cannot be both mounted and moved!",
 		)
 
-		if unmounted {
-			n.Children[i].Close()
-			n.Mapper.UnMap(n.Children[i])
+		f.children[i] = childFrame{
+			Component: newChild,
+			mounted:   mounted,
+			unmounted: unmounted,
+			moved:     moved,
 		}
 
-		n.Children[i].Component = newChild
+		if !shouldUpdate {
+			continue
+		}
 
-		if mounted {
-			n.Children[i].Mount(&n.Children[i])
+		if p.interrupt != nil && p.interrupt() {
+			debug.Log("%s render interrupted at child %d", c.Name(), i)
+			return nil, errInterrupted
 		}
 
-		if shouldUpdate {
-			n.Children[i].Update()
+		switch {
+		// a newly mounted child starts
+		// with no previous state
+		case mounted:
+			oldNode = new(Node)
+		case moved != nil:
+			oldNode = moved
 		}
 
+		p.at = append(p.at, segmentOf(newChild, i))
+		f.children[i].frame, err = oldNode.render(newChild, p)
+		p.at = p.at[:len(p.at)-1]
+
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return
 
 }
 
+// The commit function applies a rendered frame to this Node,
+// making c its Component and calling Map, UnMap, Mount and Close
+// as needed.
+func (n *Node) commit(c Component, f *frame, p *pass) {
+	n.Component = c
+
+	// Tell the mapper this Component has updated.
+	if m, ok := n.Mapper.(PathMapper); ok {
+		m.MapPath(n.Path(), n.Component)
+	} else {
+		n.Mapper.Map(n.Component)
+	}
+
+	p.mapped[n] = true
+
+	debug.Log("%s mapper updated", n.Component.Name())
+
+	if len(f.children) > len(n.Children) {
+		debug.Log(
+			"%s making new Node.Children",
+			n.Component.Name(),
+		)
+
+		n.Children = make([]*Node, len(f.children))
+		for i := range n.Children {
+			n.Children[i] = n.newChild(i)
+		}
+	}
+
+	n.previouslyRendered = true
+	n.watch(f.deps)
+
+	if cc, ok := c.(Committed); ok {
+		p.committed = append(p.committed, cc)
+	}
+
+	for i, cf := range f.children {
+		// the slot is now empty or holds a new Component;
+		// either way, its old Node must go.
+		if cf.unmounted {
+			n.Children[i].close(p)
+			n.Children[i] = n.newChild(i)
+		}
+
+		if cf.moved != nil {
+			cf.moved.parent, cf.moved.index = n, i
+			n.Children[i] = cf.moved
+		}
+
+		child := n.Children[i]
+		child.Component = cf.Component
+
+		if cf.mounted {
+			child.register()
+			child.Mount(child)
+		}
+
+		if cf.frame != nil {
+			child.commit(cf.Component, cf.frame, p)
+		}
+
+		if cf.moved != nil {
+			child.remap(p)
+		}
+	}
+}
+
+// remap tells a PathMapper the new Paths of this moved Node and its
+// descendants, except those already mapped in the pass p.
+func (n *Node) remap(p *pass) {
+	m, ok := n.Mapper.(PathMapper)
+	if !ok {
+		return
+	}
+
+	n.Walk(func(d *Node) error {
+		if !p.mapped[d] {
+			m.MapPath(d.Path(), d.Component)
+			p.mapped[d] = true
+		}
+
+		return nil
+	})
+}
+
+// The close function closes the Component of this Node and its
+// descendants, except those moving elsewhere in the pass p.
+func (n *Node) close(p *pass) {
+	if _, moving := p.moved[n]; moving || n.Component == nil {
+		return
+	}
+
+	n.unwatch()
+	n.unregister()
+	n.blur()
+	n.Close()
+
+	if m, ok := n.Mapper.(PathMapper); ok {
+		m.UnMapPath(n.Path(), n.Component)
+	} else {
+		n.Mapper.UnMap(n.Component)
+	}
+
+	for _, child := range n.Children {
+		child.close(p)
+	}
+
+	// any late updates requested
+	// by the Component do nothing.
+	n.Component = nil
+}
+
+// The update function re-renders this Node and its children,
+// then commits the result to the Mapper.
+//
+// If interrupt is non-nil, it is called between rendering each
+// Node, and if it returns true the update is abandoned before anything
+// is committed, returning errInterrupted.
+func (n *Node) update(interrupt func() bool) (err error) {
+	// the Node has been closed since
+	// the update was requested.
+	if n.Component == nil {
+		return
+	}
+
+	p := n.newPass(interrupt)
+
+	f, err := n.render(n.Component, p)
+	if err != nil {
+		return
+	}
+
+	if err = p.check(); err != nil {
+		return
+	}
+
+	n.commit(n.Component, f, p)
+
+	if c, ok := n.Mapper.(Committer); ok {
+		c.Commit()
+	}
+
+	for _, c := range p.committed {
+		c.Committed()
+	}
+
+	return
+}
+
 // The Update function is called each time a Node is constructed
-// for the first time, or its state changes.
+// for the first time, or its state changes. It is the same as
+// UpdatePriority(BackgroundPriority).
+func (n *Node) Update() { n.UpdatePriority(BackgroundPriority) }
+
+// The UpdatePriority function constructs new Node.Children and
+// determines if any have changed.
 //
-// The Update function constructs new Node.Children and determines if
-// any have changed.
+// If the Node belongs to a Scheduler, the update is queued with
+// Priority p to be performed by the Scheduler. Otherwise it is
+// performed immediately, unless the tree is already updating, in
+// which case it is performed once that update has been committed.
+// See DefaultCascadeLimit.
 //
 // If an error occurs, it is passed to the Mapper via Mapper.Error().
-func (n *Node) Update() {
-	if err := n.update(); err != nil {
-		debug.Log("[%s] ERROR: %s", reflect.TypeOf(n.Component), err)
-
-		n.Mapper.Error(
-			n.Component,
-			fmt.Errorf(
-				"Update error in Component %s: %s",
-				reflect.TypeOf(n.Component),
-				err,
-			),
-		)
+func (n *Node) UpdatePriority(p Priority) {
+	if n.scheduler != nil {
+		n.scheduler.schedule(n, p)
+		return
+	}
+
+	n.updateNow()
+}
+
+// Do calls f on the goroutine updating this Node's tree, between
+// updates, so that f may read and change the tree, for example to
+// deliver input to its Components, without racing with an update.
+//
+// If the Node belongs to a Scheduler, f is queued to be called by
+// the Scheduler, as by Scheduler.Do. Otherwise it is called
+// immediately, unless the tree is already updating, in which case
+// it is called once that update has been committed.
+func (n *Node) Do(f func()) {
+	if n.scheduler != nil {
+		n.scheduler.Do(f)
+		return
 	}
+
+	n.exclusively(deferral{node: n, do: f})
+}
+
+// report passes err, if any, to the Mapper.
+func (n *Node) report(err error) {
+	if err == nil {
+		return
+	}
+
+	c, path := n.Component, Path(nil)
+	if u, ok := err.(updateError); ok {
+		c, path = u.Component, u.path
+	}
+
+	debug.Log("[%s] ERROR: %s", reflect.TypeOf(c), err)
+
+	m, ok := n.Mapper.(PathMapper)
+	if !ok {
+		n.Mapper.Error(c, err)
+		return
+	}
+
+	if path == nil {
+		path = n.Path()
+	}
+
+	m.ErrorPath(path, c, err)
 }
 
 type StateController interface {
+	// Update requests a re-render at BackgroundPriority.
 	Update()
 }
 
@@ -138,3 +138,21 @@ var _ = Describe("Tree Update", func() {
 		}
 	})
 })
+
+var _ = Describe("Unmount", func() {
+	It("should close and unmap every Component", func() {
+		var rec treetest.Recorder
+		child := &treetest.StaticComponent{Id: "child"}
+		root := &treetest.StaticComponent{Id: "root", Children: []Component{child}}
+
+		n := NewNode(root, &rec)
+		n.Unmount()
+
+		Expect(root.CloseCalls).To(HaveLen(1))
+		Expect(child.CloseCalls).To(HaveLen(1))
+		Expect(&rec).To(treetest.HaveClosed("root", "root/0"))
+
+		n.Update()
+		Expect(root.RenderCalls).To(HaveLen(1))
+	})
+})
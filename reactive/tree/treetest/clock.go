@@ -0,0 +1,202 @@
+package treetest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"zemn.me/reactive/clock"
+	"zemn.me/reactive/tree"
+)
+
+var _ clock.Clock = &Clock{}
+
+// Epoch is when a Clock made by NewScheduler starts.
+var Epoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// A Clock is a clock.Clock whose time only passes when
+// it is advanced, firing the timers that come due in order.
+//
+// Functions passed to AfterFunc are called by the goroutine
+// calling Advance, so anything they do has happened by the time
+// Advance returns. Channels returned by After and NewTicker are
+// sent to in the same way, but what receives from them runs
+// in its own time.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*timer
+	seq    int
+}
+
+type timer struct {
+	when time.Time
+
+	// if non-zero, the timer
+	// fires every period
+	period time.Duration
+
+	fire func(now time.Time)
+
+	// for timers at the same time,
+	// the order they were made in
+	seq int
+}
+
+// NewClock returns a Clock whose time is now.
+func NewClock(now time.Time) *Clock { return &Clock{now: now} }
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.add(d, 0, func(now time.Time) { ch <- now })
+	return ch
+}
+
+func (c *Clock) AfterFunc(d time.Duration, f func()) clock.Timer {
+	return stopper{c, c.add(d, 0, func(time.Time) { f() })}
+}
+
+func (c *Clock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("treetest: non-positive interval for NewTicker")
+	}
+
+	ch := make(chan time.Time, 1)
+	t := c.add(d, d, func(now time.Time) {
+		// like a time.Ticker, drop
+		// ticks for slow receivers
+		select {
+		case ch <- now:
+		default:
+		}
+	})
+
+	return ticker{stopper{c, t}, ch}
+}
+
+// Advance moves the time forward by d, firing each
+// timer that comes due, in the order they come due.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		if len(c.timers) == 0 || c.timers[0].when.After(end) {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = t.when
+
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+			c.insert(t)
+		}
+		now, fire := c.now, t.fire
+		c.mu.Unlock()
+
+		fire(now)
+	}
+}
+
+// Next returns how long until the next timer comes
+// due, or false if no timers are waiting.
+func (c *Clock) Next() (d time.Duration, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.timers) == 0 {
+		return
+	}
+
+	return c.timers[0].when.Sub(c.now), true
+}
+
+// Waiting returns the number of timers waiting to fire.
+func (c *Clock) Waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+func (c *Clock) add(d, period time.Duration, fire func(time.Time)) *timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	t := &timer{when: c.now.Add(d), period: period, fire: fire, seq: c.seq}
+	c.insert(t)
+
+	return t
+}
+
+// insert adds t to the timers, in the order they come due.
+func (c *Clock) insert(t *timer) {
+	i := sort.Search(len(c.timers), func(i int) bool {
+		o := c.timers[i]
+		return o.when.After(t.when) || (o.when.Equal(t.when) && o.seq > t.seq)
+	})
+
+	c.timers = append(c.timers, nil)
+	copy(c.timers[i+1:], c.timers[i:])
+	c.timers[i] = t
+}
+
+type stopper struct {
+	c *Clock
+	t *timer
+}
+
+func (s stopper) Stop() bool {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+
+	for i, t := range s.c.timers {
+		if t == s.t {
+			s.c.timers = append(s.c.timers[:i], s.c.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+type ticker struct {
+	stopper
+	ch chan time.Time
+}
+
+func (t ticker) C() <-chan time.Time { return t.ch }
+func (t ticker) Stop()               { t.stopper.Stop() }
+
+// A Scheduler is a tree.Scheduler with a virtual Clock, for stepping
+// through the updates of a tree and the passing of time by hand:
+//
+//	s := treetest.NewScheduler()
+//	s.NewNode(&Poller{Clock: s.Clock}, &rec)
+//	s.Flush()
+//
+//	s.Advance(time.Second)
+//	s.Step() // the poller's update
+type Scheduler struct {
+	*tree.Scheduler
+	*Clock
+}
+
+// NewScheduler returns a Scheduler whose Clock starts at Epoch.
+func NewScheduler() *Scheduler {
+	return &Scheduler{tree.NewScheduler(), NewClock(Epoch)}
+}
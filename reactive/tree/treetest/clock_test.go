@@ -0,0 +1,140 @@
+package treetest_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive/clock"
+	"zemn.me/reactive/tree"
+	. "zemn.me/reactive/tree/treetest"
+)
+
+// a poller counts the seconds since it was mounted.
+type poller struct {
+	*StaticComponent
+	Clock   clock.Clock
+	Seconds *int
+	timer   clock.Timer
+}
+
+func (p *poller) Mount(s tree.StateController) {
+	var tick func()
+	tick = func() {
+		*p.Seconds++
+		s.Update()
+		p.timer = p.Clock.AfterFunc(time.Second, tick)
+	}
+
+	p.timer = p.Clock.AfterFunc(time.Second, tick)
+}
+
+func (p *poller) Close() { p.timer.Stop() }
+
+var _ = Describe("Clock", func() {
+	var (
+		c     *Clock
+		fired []string
+	)
+
+	BeforeEach(func() {
+		c = NewClock(Epoch)
+		fired = nil
+	})
+
+	record := func(name string) func() {
+		return func() { fired = append(fired, name) }
+	}
+
+	It("should not pass time by itself", func() {
+		Expect(c.Now()).To(Equal(Epoch))
+		Expect(c.After(0)).ToNot(Receive())
+	})
+
+	It("should fire timers in the order they come due", func() {
+		c.AfterFunc(2*time.Second, record("b"))
+		c.AfterFunc(time.Second, record("a"))
+		c.AfterFunc(2*time.Second, record("c"))
+		c.AfterFunc(3*time.Second, record("d"))
+
+		c.Advance(2 * time.Second)
+		Expect(fired).To(Equal([]string{"a", "b", "c"}))
+		Expect(c.Now()).To(Equal(Epoch.Add(2 * time.Second)))
+		Expect(c.Waiting()).To(Equal(1))
+
+		d, ok := c.Next()
+		Expect(ok).To(BeTrue())
+		Expect(d).To(Equal(time.Second))
+	})
+
+	It("should send on the channels of After and tickers", func() {
+		after := c.After(time.Second)
+		t := c.NewTicker(time.Second)
+
+		c.Advance(time.Second)
+		Expect(after).To(Receive(Equal(Epoch.Add(time.Second))))
+		Expect(t.C()).To(Receive(Equal(Epoch.Add(time.Second))))
+
+		c.Advance(3 * time.Second)
+		Expect(t.C()).To(Receive(Equal(Epoch.Add(2 * time.Second))))
+		Expect(t.C()).ToNot(Receive())
+
+		t.Stop()
+		c.Advance(time.Second)
+		Expect(t.C()).ToNot(Receive())
+	})
+
+	It("should not fire stopped timers", func() {
+		t := c.AfterFunc(time.Second, record("a"))
+		Expect(t.Stop()).To(BeTrue())
+		Expect(t.Stop()).To(BeFalse())
+
+		c.Advance(time.Hour)
+		Expect(fired).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Scheduler", func() {
+	var (
+		s       *Scheduler
+		rec     Recorder
+		seconds int
+		p       *poller
+		n       *tree.Node
+	)
+
+	BeforeEach(func() {
+		rec.Clear()
+		seconds = 0
+		s = NewScheduler()
+		p = &poller{StaticComponent: &StaticComponent{Id: "poller"}, Clock: s.Clock, Seconds: &seconds}
+		n = s.NewNode(p, &rec)
+		s.Flush()
+		rec.Clear()
+	})
+
+	It("should only update Components as time is advanced", func() {
+		Expect(s.Pending()).To(Equal(0))
+
+		s.Advance(time.Second)
+		Expect(seconds).To(Equal(1))
+		Expect(s.Pending()).To(Equal(1))
+
+		Expect(s.Step()).To(BeTrue())
+		Expect(rec.Events).To(HaveLen(1))
+
+		s.Advance(3 * time.Second)
+		Expect(seconds).To(Equal(4))
+		Expect(s.Pending()).To(Equal(1))
+	})
+
+	It("should stop the timers of closed Components", func() {
+		Expect(s.Waiting()).To(Equal(1))
+		n.Unmount()
+		Expect(s.Waiting()).To(Equal(0))
+
+		s.Advance(time.Hour)
+		Expect(seconds).To(Equal(0))
+	})
+})
@@ -0,0 +1,64 @@
+package treetest
+
+import "strings"
+
+// Diff returns a line by line diff of want and got. Lines only in
+// want are prefixed with "-", lines only in got with "+", and lines
+// in both with " ".
+func Diff(want, got []string) string {
+	var b strings.Builder
+	for _, l := range diff(want, got) {
+		b.WriteString(l + "\n")
+	}
+
+	return b.String()
+}
+
+func diff(want, got []string) (lines []string) {
+	// lcs[i][j] is the length of the longest common
+	// subsequence of want[i:] and got[j:].
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			switch {
+			case want[i] == got[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	line := func(prefix, s string) { lines = append(lines, prefix+s) }
+
+	i, j := 0, 0
+	for i < len(want) && j < len(got) {
+		switch {
+		case want[i] == got[j]:
+			line(" ", want[i])
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			line("-", want[i])
+			i++
+		default:
+			line("+", got[j])
+			j++
+		}
+	}
+
+	for ; i < len(want); i++ {
+		line("-", want[i])
+	}
+
+	for ; j < len(got); j++ {
+		line("+", got[j])
+	}
+
+	return
+}
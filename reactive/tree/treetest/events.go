@@ -0,0 +1,57 @@
+package treetest
+
+import (
+	"fmt"
+	"strings"
+
+	"zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/event"
+)
+
+// An Event is a call made to a Recorder.
+type Event struct {
+	Kind event.Kind
+
+	// the Path of the Component, if the Recorder was told it
+	Path      tree.Path
+	Component tree.Component
+
+	// for an event.Error, the error
+	Err error
+
+	// the update the Event happened in, counting from 1.
+	// An event.Error has the Frame of the update it stopped
+	// from being committed.
+	Frame int
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("frame %d: %s %s", e.Frame, e.Kind, e.Path)
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+
+	return s
+}
+
+// Log returns the Events recorded, one per line.
+func (r *Recorder) Log() string {
+	var b strings.Builder
+	for _, e := range r.Events {
+		fmt.Fprintln(&b, e)
+	}
+
+	return b.String()
+}
+
+// Paths returns the Paths of the Events of
+// the given kind, in order.
+func (r *Recorder) Paths(kind event.Kind) (paths []string) {
+	for _, e := range r.Events {
+		if e.Kind == kind {
+			paths = append(paths, e.Path.String())
+		}
+	}
+
+	return
+}
@@ -0,0 +1,157 @@
+package treetest
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// LeakTimeout is how long the function returned by TrackGoroutines
+// waits for goroutines to exit before reporting them as leaked.
+var LeakTimeout = time.Second
+
+// ignoredPackages are packages outside the standard library
+// whose goroutines are not Component code.
+var ignoredPackages = []string{
+	"github.com/onsi/",
+}
+
+// TrackGoroutines records the goroutines running now, and returns a
+// function which reports an error giving the stacks of any goroutines
+// started since which are running Component code. It is used to check
+// that Components stop what they start when closed:
+//
+//	leaked := treetest.TrackGoroutines()
+//
+//	n := tree.NewNode(root, &rec)
+//	n.Unmount()
+//
+//	Expect(leaked()).To(Succeed())
+//
+// A goroutine is running Component code if any function in its stack,
+// or the function that started it, is from outside the standard library
+// and the test framework. Since goroutines may take a moment to notice
+// they should stop, leaked waits up to LeakTimeout for them to exit.
+func TrackGoroutines() (leaked func() error) {
+	before := make(map[int]bool)
+	for _, g := range goroutines() {
+		before[g.id] = true
+	}
+
+	return func() error {
+		deadline := time.Now().Add(LeakTimeout)
+
+		for {
+			var leaks []string
+			for _, g := range goroutines() {
+				if !before[g.id] && g.component() {
+					leaks = append(leaks, g.stack)
+				}
+			}
+
+			if len(leaks) == 0 {
+				return nil
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf(
+					"%d goroutines leaked:\n\n%s",
+					len(leaks), strings.Join(leaks, "\n\n"),
+				)
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+type goroutine struct {
+	id    int
+	stack string
+
+	// the functions in the stack,
+	// and the one which started it.
+	funcs []string
+}
+
+// goroutines returns every running goroutine.
+func goroutines() (gs []goroutine) {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		g := goroutine{stack: string(stack)}
+
+		lines := strings.Split(g.stack, "\n")
+		if _, err := fmt.Sscanf(lines[0], "goroutine %d", &g.id); err != nil {
+			continue
+		}
+
+		for _, line := range lines[1:] {
+			if strings.HasPrefix(line, "\t") || line == "" {
+				continue
+			}
+
+			if strings.HasPrefix(line, "created by ") {
+				line = strings.TrimPrefix(line, "created by ")
+				if i := strings.Index(line, " in goroutine "); i >= 0 {
+					line = line[:i]
+				}
+			} else if i := strings.LastIndex(line, "("); i >= 0 {
+				line = line[:i]
+			}
+
+			g.funcs = append(g.funcs, line)
+		}
+
+		gs = append(gs, g)
+	}
+
+	return
+}
+
+// component reports whether the goroutine
+// is running Component code.
+func (g goroutine) component() bool {
+	for _, fn := range g.funcs {
+		if pkg := packageOf(fn); userPackage(pkg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// packageOf returns the package path of
+// a function named as in a stack trace.
+func packageOf(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	if dot := strings.Index(fn[slash+1:], "."); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+
+	return fn
+}
+
+// userPackage reports whether pkg is outside the
+// standard library and the test framework.
+func userPackage(pkg string) bool {
+	for _, ignored := range ignoredPackages {
+		if strings.HasPrefix(pkg, ignored) {
+			return false
+		}
+	}
+
+	first := strings.SplitN(pkg, "/", 2)[0]
+	return pkg == "main" || strings.Contains(first, ".")
+}
@@ -0,0 +1,59 @@
+package treetest_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive/tree"
+	. "zemn.me/reactive/tree/treetest"
+)
+
+// a spinner starts a goroutine when mounted,
+// and stops it when closed, unless it is leaky.
+type spinner struct {
+	*StaticComponent
+	leaky bool
+	stop  chan struct{}
+}
+
+func (s spinner) Mount(tree.StateController) {
+	go func() { <-s.stop }()
+}
+
+func (s spinner) Close() {
+	if !s.leaky {
+		close(s.stop)
+	}
+}
+
+var _ = Describe("TrackGoroutines", func() {
+	var timeout time.Duration
+
+	BeforeEach(func() { timeout, LeakTimeout = LeakTimeout, 50*time.Millisecond })
+	AfterEach(func() { LeakTimeout = timeout })
+
+	It("should pass when closed Components stop their goroutines", func() {
+		leaked := TrackGoroutines()
+
+		n := tree.NewNode(spinner{&StaticComponent{}, false, make(chan struct{})}, &Recorder{})
+		n.Unmount()
+
+		Expect(leaked()).To(Succeed())
+	})
+
+	It("should report the goroutines closed Components leave running", func() {
+		leaked := TrackGoroutines()
+
+		s := spinner{&StaticComponent{}, true, make(chan struct{})}
+		n := tree.NewNode(s, &Recorder{})
+		n.Unmount()
+		defer close(s.stop)
+
+		err := leaked()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("1 goroutines leaked"))
+		Expect(err.Error()).To(ContainSubstring("spinner.Mount"))
+	})
+})
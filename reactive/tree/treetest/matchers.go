@@ -0,0 +1,123 @@
+package treetest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+
+	"zemn.me/reactive/tree/event"
+)
+
+// HaveMappedInOrder succeeds if a Recorder has mapped Components at
+// each of paths, in the order given, though perhaps with other
+// Components mapped in between:
+//
+//	Expect(&rec).To(HaveMappedInOrder("root", "root/1", "root/0"))
+func HaveMappedInOrder(paths ...string) types.GomegaMatcher {
+	return &mappedInOrder{paths: paths}
+}
+
+type mappedInOrder struct{ paths, mapped []string }
+
+func (m *mappedInOrder) Match(actual interface{}) (success bool, err error) {
+	r, err := recorder("HaveMappedInOrder", actual)
+	if err != nil {
+		return
+	}
+
+	m.mapped = r.Paths(event.Map)
+
+	i := 0
+	for _, p := range m.mapped {
+		if i < len(m.paths) && p == m.paths[i] {
+			i++
+		}
+	}
+
+	return i == len(m.paths), nil
+}
+
+func (m *mappedInOrder) FailureMessage(interface{}) string {
+	return fmt.Sprintf(
+		"Expected Components to have been mapped, in order, at:\n%s"+
+			"Mapped paths (-expected, +mapped):\n%s",
+		indent(m.paths), indent(diff(m.paths, m.mapped)),
+	)
+}
+
+func (m *mappedInOrder) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf(
+		"Expected Components not to have been mapped, in order, at:\n%s"+
+			"Mapped paths:\n%s",
+		indent(m.paths), indent(m.mapped),
+	)
+}
+
+// HaveClosed succeeds if a Recorder has unmapped
+// Components at each of paths, in any order.
+func HaveClosed(paths ...string) types.GomegaMatcher {
+	return &closed{paths: paths}
+}
+
+type closed struct{ paths, closed, missing []string }
+
+func (c *closed) Match(actual interface{}) (success bool, err error) {
+	r, err := recorder("HaveClosed", actual)
+	if err != nil {
+		return
+	}
+
+	c.closed = r.Paths(event.UnMap)
+
+	seen := make(map[string]bool, len(c.closed))
+	for _, p := range c.closed {
+		seen[p] = true
+	}
+
+	c.missing = nil
+	for _, p := range c.paths {
+		if !seen[p] {
+			c.missing = append(c.missing, p)
+		}
+	}
+
+	return len(c.missing) == 0, nil
+}
+
+func (c *closed) FailureMessage(interface{}) string {
+	return fmt.Sprintf(
+		"Expected Components to have been closed at:\n%s"+
+			"but these were not:\n%s"+
+			"Closed paths:\n%s",
+		indent(c.paths), indent(c.missing), indent(c.closed),
+	)
+}
+
+func (c *closed) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf(
+		"Expected Components not to have all been closed at:\n%s"+
+			"Closed paths:\n%s",
+		indent(c.paths), indent(c.closed),
+	)
+}
+
+func recorder(matcher string, actual interface{}) (*Recorder, error) {
+	switch r := actual.(type) {
+	case *Recorder:
+		return r, nil
+	case Recorder:
+		return &r, nil
+	}
+
+	return nil, fmt.Errorf("%s expects a treetest.Recorder, but got %T", matcher, actual)
+}
+
+func indent(lines []string) string {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString("    " + l + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package treetest_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/event"
+	. "zemn.me/reactive/tree/treetest"
+)
+
+// failing always fails to render.
+type failing struct{ *StaticComponent }
+
+func (failing) Render() ([]tree.Component, error) { return nil, errors.New("failed") }
+
+var _ = Describe("Recorder", func() {
+	var (
+		rec     Recorder
+		root, a *StaticComponent
+		b       *StaticComponent
+		node    *tree.Node
+	)
+
+	BeforeEach(func() {
+		rec.Clear()
+		a = &StaticComponent{Id: "a"}
+		b = &StaticComponent{Id: "b"}
+		root = &StaticComponent{Id: "root", Children: []tree.Component{a, b, nil}}
+		node = tree.NewNode(root, &rec)
+	})
+
+	It("should record the Path and Frame of each Event", func() {
+		root.Children = []tree.Component{nil, b, nil}
+		node.Update()
+
+		Expect(rec.Log()).To(Equal(
+			"frame 1: map root\n" +
+				"frame 1: map root/0\n" +
+				"frame 1: map root/1\n" +
+				"frame 2: map root\n" +
+				"frame 2: unmap root/0\n",
+		))
+		Expect(rec.Frame).To(Equal(2))
+	})
+
+	It("should record the Path of a Component which fails to update", func() {
+		root.Children = []tree.Component{a, b, failing{&StaticComponent{}}}
+		node.Update()
+
+		last := rec.Events[len(rec.Events)-1]
+		Expect(last.Kind).To(Equal(event.Error))
+		Expect(last.Path.String()).To(Equal("root/2"))
+		Expect(last.Frame).To(Equal(2))
+		Expect(rec.Frame).To(Equal(1))
+	})
+
+	Describe("HaveMappedInOrder", func() {
+		It("should match paths mapped in order", func() {
+			Expect(&rec).To(HaveMappedInOrder("root", "root/1"))
+			Expect(rec).To(HaveMappedInOrder("root/0", "root/1"))
+		})
+
+		It("should not match paths mapped out of order", func() {
+			Expect(&rec).ToNot(HaveMappedInOrder("root/1", "root/0"))
+		})
+
+		It("should show the difference", func() {
+			m := HaveMappedInOrder("root", "root/2", "root/1")
+			Expect(m.Match(&rec)).To(BeFalse())
+			Expect(m.FailureMessage(&rec)).To(ContainSubstring(
+				"     root\n" +
+					"    -root/2\n" +
+					"    +root/0\n" +
+					"     root/1\n",
+			))
+		})
+
+		It("should only match Recorders", func() {
+			_, err := HaveMappedInOrder().Match(1)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("HaveClosed", func() {
+		BeforeEach(func() {
+			root.Children = []tree.Component{nil, nil, nil}
+			node.Update()
+		})
+
+		It("should match closed paths in any order", func() {
+			Expect(&rec).To(HaveClosed("root/1", "root/0"))
+		})
+
+		It("should list the paths that were not closed", func() {
+			m := HaveClosed("root/0", "root/2")
+			Expect(m.Match(&rec)).To(BeFalse())
+			Expect(m.FailureMessage(&rec)).To(ContainSubstring("but these were not:\n    root/2\n"))
+		})
+	})
+})
+
+var _ = Describe("Diff", func() {
+	It("should mark lines only in one side", func() {
+		Expect(Diff(
+			[]string{"a", "b", "c"},
+			[]string{"a", "c", "d"},
+		)).To(Equal(" a\n-b\n c\n+d\n"))
+	})
+})
@@ -0,0 +1,225 @@
+package treetest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+
+	"zemn.me/reactive/tree"
+)
+
+// The -update flag of tests using treetest makes MatchGolden
+// write golden files rather than check against them:
+//
+//	go test ./term/... -update
+//
+// Since only tests importing treetest have the flag, UpdateEnv
+// may be set instead when testing other packages too.
+var update = flag.Bool("update", false, "write the golden files of treetest.MatchGolden rather than checking against them")
+
+// UpdateEnv is the environment variable which, if set to anything but
+// the empty string, has the same effect as the -update flag:
+//
+//	TREETEST_UPDATE=1 go test ./...
+const UpdateEnv = "TREETEST_UPDATE"
+
+// updating reports whether golden files are being updated.
+func updating() bool { return *update || os.Getenv(UpdateEnv) != "" }
+
+var (
+	componentType       = reflect.TypeOf((*tree.Component)(nil)).Elem()
+	stateControllerType = reflect.TypeOf((*tree.StateController)(nil)).Elem()
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// maximum depth of props to snapshot
+const snapshotDepth = 6
+
+// Snapshot describes the tree at n in a stable text format, one Node
+// per line, giving the name and type of each Component, and its
+// exported props. Props which are Components or StateControllers,
+// functions or channels are left out; the Components are described
+// by the lines of the Nodes they are rendered at. So are fields
+// tagged `snapshot:"-"`, such as the instrumentation of a
+// StaticComponent, which describe how a Component was used rather
+// than what it renders. Props which are fmt.Stringers are described
+// by their String().
+func Snapshot(n *tree.Node) string {
+	var b strings.Builder
+	snapshot(&b, n, 0)
+	return b.String()
+}
+
+func snapshot(b *strings.Builder, n *tree.Node, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(n.Path()[n.Depth()] + ": ")
+
+	if n.Component == nil {
+		b.WriteString("nil\n")
+		return
+	}
+
+	fmt.Fprintf(b, "%s (%s) ", n.Name(), reflect.TypeOf(n.Component))
+	props(b, reflect.ValueOf(n.Component), 0)
+	b.WriteString("\n")
+
+	for _, child := range n.Children {
+		snapshot(b, child, depth+1)
+	}
+}
+
+// props describes v.
+func props(b *strings.Builder, v reflect.Value, depth int) {
+	if !v.IsValid() {
+		b.WriteString("nil")
+		return
+	}
+
+	if depth > snapshotDepth {
+		b.WriteString("...")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+	}
+
+	if v.Type().Implements(stringerType) && v.CanInterface() {
+		b.WriteString(v.Interface().(fmt.Stringer).String())
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		props(b, v.Elem(), depth)
+	case reflect.Struct:
+		b.WriteString("{")
+		first := true
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" || f.Tag.Get("snapshot") == "-" || skipped(f.Type) {
+				continue
+			}
+
+			if !first {
+				b.WriteString(", ")
+			}
+			first = false
+
+			b.WriteString(f.Name + ": ")
+			props(b, v.Field(i), depth+1)
+		}
+		b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+
+			props(b, v.Index(i), depth+1)
+		}
+		b.WriteString("]")
+	case reflect.Map:
+		entries := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			var e strings.Builder
+			props(&e, k, depth+1)
+			e.WriteString(": ")
+			props(&e, v.MapIndex(k), depth+1)
+			entries = append(entries, e.String())
+		}
+
+		sort.Strings(entries)
+		b.WriteString("{" + strings.Join(entries, ", ") + "}")
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		b.WriteString(v.Type().String())
+	default:
+		fmt.Fprint(b, v)
+	}
+}
+
+// skipped reports whether props of
+// type t are left out of Snapshots.
+func skipped(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	case reflect.Slice, reflect.Array:
+		return skipped(t.Elem())
+	}
+
+	return t.Implements(componentType) || t.Implements(stateControllerType)
+}
+
+// MatchGolden succeeds if the Snapshot of a *tree.Node, or a string,
+// is the same as the golden file testdata/name.golden. If the tests
+// are run with -update, or UpdateEnv is set, it writes the golden
+// file instead.
+//
+// When it fails, it shows the difference between the two.
+func MatchGolden(name string) types.GomegaMatcher {
+	return &golden{path: filepath.Join("testdata", name+".golden")}
+}
+
+type golden struct {
+	path      string
+	got, want []string
+}
+
+func (g *golden) Match(actual interface{}) (success bool, err error) {
+	var got string
+	switch v := actual.(type) {
+	case *tree.Node:
+		got = Snapshot(v)
+	case string:
+		got = v
+	default:
+		return false, fmt.Errorf("MatchGolden expects a *tree.Node or string, but got %T", actual)
+	}
+
+	if updating() {
+		if err = os.MkdirAll(filepath.Dir(g.path), 0o755); err != nil {
+			return
+		}
+
+		return true, os.WriteFile(g.path, []byte(got), 0o644)
+	}
+
+	want, err := os.ReadFile(g.path)
+	if err != nil {
+		return false, fmt.Errorf("%w; run the tests with -update or %s=1 to create it", err, UpdateEnv)
+	}
+
+	g.got, g.want = lines(got), lines(string(want))
+	return got == string(want), nil
+}
+
+func (g *golden) FailureMessage(interface{}) string {
+	return fmt.Sprintf(
+		"Expected to match %s (-golden, +actual):\n%s"+
+			"Run the tests with -update or %s=1 if the change is intended.",
+		g.path, indent(diff(g.want, g.got)), UpdateEnv,
+	)
+}
+
+func (g *golden) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected not to match %s", g.path)
+}
+
+func lines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
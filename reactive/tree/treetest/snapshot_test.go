@@ -0,0 +1,92 @@
+package treetest_test
+
+import (
+	"flag"
+	"image"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive/tree"
+	. "zemn.me/reactive/tree/treetest"
+)
+
+// a widget has props of many kinds.
+type widget struct {
+	*StaticComponent
+	Title    string
+	Bounds   image.Rectangle
+	Tags     map[string]int
+	OnClick  func()
+	Child    tree.Component
+	internal int
+}
+
+var _ = Describe("Snapshot", func() {
+	var (
+		rec  Recorder
+		node *tree.Node
+	)
+
+	BeforeEach(func() {
+		rec.Clear()
+		leaf := &StaticComponent{Id: "leaf"}
+
+		node = tree.NewNode(&StaticComponent{Id: "root", Children: []tree.Component{
+			widget{
+				StaticComponent: &StaticComponent{Id: "widget", Children: []tree.Component{leaf}},
+				Title:           "hello",
+				Bounds:          image.Rect(0, 0, 10, 2),
+				Tags:            map[string]int{"b": 2, "a": 1},
+				OnClick:         func() {},
+				Child:           leaf,
+				internal:        1,
+			},
+			nil,
+		}}, &rec)
+	})
+
+	It("should match the golden file", func() {
+		Expect(node).To(MatchGolden("snapshot"))
+	})
+
+	Describe("updating", func() {
+		var env, flagged string
+
+		BeforeEach(func() {
+			env, flagged = os.Getenv(UpdateEnv), flag.Lookup("update").Value.String()
+			os.Unsetenv(UpdateEnv)
+			Expect(flag.Set("update", "false")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.Setenv(UpdateEnv, env)
+			Expect(flag.Set("update", flagged)).To(Succeed())
+		})
+
+		When("run with -update", func() {
+			BeforeEach(func() { Expect(flag.Set("update", "true")).To(Succeed()) })
+			AfterEach(func() { Expect(os.Remove(filepath.Join("testdata", "updated.golden"))).To(Succeed()) })
+
+			It("should write the golden file", func() {
+				Expect("root: updated\n").To(MatchGolden("updated"))
+				Expect(os.ReadFile(filepath.Join("testdata", "updated.golden"))).To(Equal([]byte("root: updated\n")))
+			})
+		})
+
+		When("not updating", func() {
+			It("should show how a snapshot differs", func() {
+				m := MatchGolden("snapshot")
+				Expect(m.Match("root: nothing\n")).To(BeFalse())
+				Expect(m.FailureMessage(nil)).To(ContainSubstring("    +root: nothing\n"))
+			})
+
+			It("should explain missing golden files", func() {
+				_, err := MatchGolden("missing").Match("")
+				Expect(err).To(MatchError(ContainSubstring("-update")))
+			})
+		})
+	})
+})
@@ -6,6 +6,7 @@ import (
 
 	"zemn.me/debug"
 	"zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/event"
 )
 
 type RecordedError struct {
@@ -13,22 +14,55 @@ type RecordedError struct {
 	Err error
 }
 
+var _ tree.PathMapper = &Recorder{}
+var _ tree.Committer = &Recorder{}
+
+// A Recorder is a Mapper which records what it is passed.
 type Recorder struct {
 	Components       []tree.Component
 	ClosedComponents []tree.Component
 	Errors           []RecordedError
+
+	// Events is every call to the Recorder, in order.
+	Events []Event
+
+	// Frame is the number of updates
+	// committed so far.
+	Frame int
 }
 
 func (r *Recorder) Clear() { *r = Recorder{} }
 
-func (r *Recorder) UnMap(c tree.Component) { r.ClosedComponents = append(r.ClosedComponents, c) }
-func (r *Recorder) Map(c tree.Component) {
+func (r *Recorder) UnMap(c tree.Component) { r.UnMapPath(nil, c) }
+func (r *Recorder) UnMapPath(p tree.Path, c tree.Component) {
+	r.ClosedComponents = append(r.ClosedComponents, c)
+	r.record(event.UnMap, p, c, nil)
+}
+
+func (r *Recorder) Map(c tree.Component) { r.MapPath(nil, c) }
+func (r *Recorder) MapPath(p tree.Path, c tree.Component) {
 	debug.Log("mapping %+v: now mapped %d components", reflect.ValueOf(c), len(r.Components)+1)
 
 	r.Components = append(r.Components, c)
+	r.record(event.Map, p, c, nil)
 }
-func (r *Recorder) Error(c tree.Component, err error) {
+
+func (r *Recorder) Error(c tree.Component, err error) { r.ErrorPath(nil, c, err) }
+func (r *Recorder) ErrorPath(p tree.Path, c tree.Component, err error) {
 	r.Errors = append(r.Errors, RecordedError{c, err})
+	r.record(event.Error, p, c, err)
+}
+
+func (r *Recorder) Commit() { r.Frame++ }
+
+func (r *Recorder) record(kind event.Kind, p tree.Path, c tree.Component, err error) {
+	r.Events = append(r.Events, Event{
+		Kind:      kind,
+		Path:      p,
+		Component: c,
+		Err:       err,
+		Frame:     r.Frame + 1,
+	})
 }
 
 type MountCall struct{ StateController tree.StateController }
@@ -38,17 +72,17 @@ type ShouldUpdateCall struct{ NewComponent tree.Component }
 type StaticComponent struct {
 	Id                string
 	Children          []tree.Component
-	MountCalls        []MountCall
-	CloseCalls        []CloseCall
-	ShouldUpdateCalls []ShouldUpdateCall
-	RenderCalls       []bool
+	MountCalls        []MountCall        `snapshot:"-"`
+	CloseCalls        []CloseCall        `snapshot:"-"`
+	ShouldUpdateCalls []ShouldUpdateCall `snapshot:"-"`
+	RenderCalls       []bool             `snapshot:"-"`
 }
 
 func (s *StaticComponent) ForceUpdate() (err error) {
 	if len(s.MountCalls) < 1 {
 		return fmt.Errorf(
-			"trying to force update on %[0]s, but"+
-				" %[0]s has no record of being mounted!",
+			"trying to force update on %[1]s, but"+
+				" %[1]s has no record of being mounted!",
 			s.Id,
 		)
 	}
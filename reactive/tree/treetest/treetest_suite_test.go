@@ -0,0 +1,13 @@
+package treetest_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTreetest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Treetest Suite")
+}
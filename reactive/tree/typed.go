@@ -0,0 +1,69 @@
+package tree
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var _ Component = Typed[struct{}]{}
+
+// A Func renders a Component from its props, which are of
+// type P. Since P is comparable, a Component made from a Func
+// need only update when its props change:
+//
+//	type greetingProps struct{ Name string }
+//
+//	var Greeting = tree.NewFunc(func(p greetingProps) []tree.Component {
+//		return []tree.Component{term.Text{Text: "hello, " + p.Name}}
+//	})
+//
+//	Greeting.With(greetingProps{Name: "world"})
+//
+// Go cannot tell whether two funcs are the same, so each Func made by
+// NewFunc is taken to render differently from every other, even if
+// made from the same func literal.
+type Func[P comparable] struct {
+	render func(props P) []Component
+}
+
+// NewFunc returns a new Func rendering with render.
+func NewFunc[P comparable](render func(props P) []Component) *Func[P] {
+	return &Func[P]{render: render}
+}
+
+// With returns the Component which renders
+// the Func with props.
+func (f *Func[P]) With(props P) Typed[P] { return Typed[P]{Props: props, Func: f} }
+
+// A Typed Component renders its Props with its Func.
+// It updates only when it is passed different Props or a
+// different Func, so it can be used without writing a
+// ShouldUpdate, or any type assertions.
+type Typed[P comparable] struct {
+	Props P
+	Func  *Func[P]
+}
+
+func (t Typed[P]) Name() string {
+	return fmt.Sprintf("Typed[%s]", reflect.TypeOf(&t.Props).Elem())
+}
+
+func (Typed[P]) Mount(StateController) {}
+func (Typed[P]) Close()                {}
+
+func (t Typed[P]) ShouldUpdate(old Component) (bool, error) {
+	o, ok := old.(Typed[P])
+	if !ok {
+		return true, nil
+	}
+
+	return o.Props != t.Props || o.Func != t.Func, nil
+}
+
+func (t Typed[P]) Render() ([]Component, error) {
+	if t.Func == nil || t.Func.render == nil {
+		return nil, fmt.Errorf("%s has no Func", t.Name())
+	}
+
+	return t.Func.render(t.Props), nil
+}
@@ -0,0 +1,91 @@
+package tree_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+type greetingProps struct {
+	Name  string
+	Times int
+}
+
+var _ = Describe("Typed", func() {
+	var (
+		rec     treetest.Recorder
+		renders []greetingProps
+		child   *treetest.StaticComponent
+		parent  *treetest.StaticComponent
+		node    *Node
+		greet   *Func[greetingProps]
+	)
+
+	BeforeEach(func() {
+		rec.Clear()
+		renders = nil
+		child = &treetest.StaticComponent{Id: "child"}
+
+		greet = NewFunc(func(p greetingProps) []Component {
+			renders = append(renders, p)
+			return []Component{child}
+		})
+
+		parent = &treetest.StaticComponent{Id: "parent"}
+		parent.Children = []Component{greet.With(greetingProps{"world", 1})}
+		node = NewNode(eagerComponent{StaticComponent: parent}, &rec)
+	})
+
+	It("should render its Func with its Props", func() {
+		Expect(renders).To(Equal([]greetingProps{{"world", 1}}))
+		Expect(rec.Components).To(ContainElement(child))
+	})
+
+	It("should be named after its Props", func() {
+		Expect(greet.With(greetingProps{}).Name()).To(Equal("Typed[tree_test.greetingProps]"))
+	})
+
+	It("should not update when given equal Props", func() {
+		parent.Children = []Component{greet.With(greetingProps{"world", 1})}
+		node.Update()
+
+		Expect(renders).To(HaveLen(1))
+	})
+
+	It("should update when given different Props", func() {
+		parent.Children = []Component{greet.With(greetingProps{"world", 2})}
+		node.Update()
+
+		Expect(renders).To(Equal([]greetingProps{{"world", 1}, {"world", 2}}))
+	})
+
+	It("should update when given a different Func of the same literal", func() {
+		var greeted []string
+		greeter := func(greeting string) *Func[greetingProps] {
+			return NewFunc(func(p greetingProps) []Component {
+				greeted = append(greeted, greeting+" "+p.Name)
+				return nil
+			})
+		}
+
+		parent.Children = []Component{greeter("hello").With(greetingProps{"world", 1})}
+		node.Update()
+		parent.Children = []Component{greeter("goodbye").With(greetingProps{"world", 1})}
+		node.Update()
+
+		Expect(greeted).To(Equal([]string{"hello world", "goodbye world"}))
+	})
+
+	It("should update when replacing a different type of Component", func() {
+		update, err := greet.With(greetingProps{}).ShouldUpdate(child)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(update).To(BeTrue())
+	})
+
+	It("should fail to render without a Func", func() {
+		_, err := Typed[greetingProps]{}.Render()
+		Expect(err).To(HaveOccurred())
+	})
+})
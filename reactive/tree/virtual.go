@@ -0,0 +1,104 @@
+package tree
+
+import "fmt"
+
+var (
+	_ Component = VirtualList{}
+	_ Committed = VirtualList{}
+)
+
+// A VirtualList is a Component which renders a window of a very long
+// list of rows, mounting Nodes only for the rows that are visible.
+//
+// A VirtualList always has Window children, each a slot which
+// is given to rows in turn: row i is rendered by Row(i) in slot
+// i % Window. When the list is scrolled by changing Offset, only
+// the slots of rows which have scrolled out of view are given new
+// rows, and because a slot keeps its Node, the new row is compared
+// to the old with ShouldUpdate rather than mounted afresh.
+//
+// Once scrolled, the order of the children is therefore not the
+// order in which the rows are displayed: the row at position p
+// of the window is in the slot Slot(Offset+p).
+//
+// Since the number of children a Component has may not change,
+// Window may not change either; it should be the most rows that will
+// ever be visible at once. Visible, which may change, is the number
+// of rows actually visible; if zero, it is Window.
+type VirtualList struct {
+	// the number of rows in the list
+	Rows int
+
+	// the first visible row
+	Offset int
+
+	// the number of slots, and visible rows
+	Window, Visible int
+
+	// Row renders row i.
+	Row func(i int) Component
+
+	// OnRange, if set, is told the range of visible rows,
+	// [start, end), each time an update of the VirtualList
+	// is committed.
+	OnRange func(start, end int)
+}
+
+func (VirtualList) Name() string          { return "virtuallist" }
+func (VirtualList) Mount(StateController) {}
+func (VirtualList) Close()                {}
+
+// ShouldUpdate always returns true; re-rendering
+// a VirtualList renders only its visible rows.
+func (VirtualList) ShouldUpdate(Component) (bool, error) { return true, nil }
+
+// VisibleRange returns the range of rows which are visible,
+// [start, end).
+func (v VirtualList) VisibleRange() (start, end int) {
+	visible := v.Visible
+	if visible == 0 || visible > v.Window {
+		visible = v.Window
+	}
+
+	start, end = v.Offset, v.Offset+visible
+
+	if end > v.Rows {
+		end = v.Rows
+	}
+
+	if start < 0 {
+		start = 0
+	}
+
+	if start > end {
+		start = end
+	}
+
+	return
+}
+
+// Slot returns the index of the child
+// in which row i is rendered.
+func (v VirtualList) Slot(i int) int { return i % v.Window }
+
+func (v VirtualList) Render() (slots []Component, err error) {
+	if v.Window <= 0 {
+		return nil, fmt.Errorf("VirtualList needs a Window of at least 1, not %d", v.Window)
+	}
+
+	start, end := v.VisibleRange()
+
+	slots = make([]Component, v.Window)
+	for i := start; i < end; i++ {
+		slots[v.Slot(i)] = v.Row(i)
+	}
+
+	return
+}
+
+// Committed calls OnRange, if set.
+func (v VirtualList) Committed() {
+	if v.OnRange != nil {
+		v.OnRange(v.VisibleRange())
+	}
+}
@@ -0,0 +1,130 @@
+package tree_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+type rowStats struct{ mounts, closes, renders int }
+
+// row is a Component rendering
+// a row of a VirtualList.
+type row struct {
+	Index int
+	stats *rowStats
+}
+
+func (r row) Name() string          { return fmt.Sprintf("row %d", r.Index) }
+func (r row) Mount(StateController) { r.stats.mounts++ }
+func (r row) Close()                { r.stats.closes++ }
+func (r row) ShouldUpdate(old Component) (bool, error) {
+	return r != old.(row), nil
+}
+func (r row) Render() ([]Component, error) {
+	r.stats.renders++
+	return nil, nil
+}
+
+var _ = Describe("VirtualList", func() {
+	var (
+		rec        treetest.Recorder
+		stats      *rowStats
+		list       VirtualList
+		start, end int
+		n          *Node
+		scroll     func(offset int)
+	)
+
+	scroll = func(offset int) {
+		list.Offset = offset
+		n.Component = list
+		n.Update()
+	}
+
+	BeforeEach(func() {
+		rec.Clear()
+		stats = new(rowStats)
+		list = VirtualList{
+			Rows:    100000,
+			Window:  10,
+			Row:     func(i int) Component { return row{i, stats} },
+			OnRange: func(s, e int) { start, end = s, e },
+		}
+
+		n = NewNode(list, &rec)
+	})
+
+	It("should only mount the visible rows", func() {
+		Expect(n.Children).To(HaveLen(10))
+		Expect(stats.mounts).To(Equal(10))
+		Expect(stats.renders).To(Equal(10))
+	})
+
+	It("should report the visible range once committed", func() {
+		Expect([]int{start, end}).To(Equal([]int{0, 10}))
+		scroll(50)
+		Expect([]int{start, end}).To(Equal([]int{50, 60}))
+	})
+
+	When("scrolled", func() {
+		BeforeEach(func() { scroll(3) })
+
+		It("should recycle Nodes instead of mounting new ones", func() {
+			Expect(stats.mounts).To(Equal(10))
+			Expect(stats.closes).To(Equal(0))
+		})
+
+		It("should only re-render rows that scrolled into view", func() {
+			Expect(stats.renders).To(Equal(10 + 3))
+		})
+
+		It("should put each row in its slot", func() {
+			Expect(n.Children[0].Component).To(Equal(row{10, stats}))
+			Expect(n.Children[3].Component).To(Equal(row{3, stats}))
+		})
+
+		It("should give the row at each position by its Slot", func() {
+			for p := 0; p < list.Window; p++ {
+				Expect(n.Children[list.Slot(list.Offset+p)].Component).To(Equal(row{3 + p, stats}))
+			}
+		})
+	})
+
+	When("an update fails", func() {
+		BeforeEach(func() {
+			list.Window = 0
+			scroll(50)
+		})
+
+		It("should not report its range", func() {
+			Expect(rec.Errors).To(HaveLen(1))
+			Expect([]int{start, end}).To(Equal([]int{0, 10}))
+		})
+	})
+
+	When("scrolled past the end", func() {
+		BeforeEach(func() { scroll(100000 - 4) })
+
+		It("should close the slots without rows", func() {
+			Expect(stats.closes).To(Equal(6))
+			Expect([]int{start, end}).To(Equal([]int{100000 - 4, 100000}))
+		})
+	})
+
+	When("fewer rows are visible than there are slots", func() {
+		BeforeEach(func() {
+			list.Visible = 4
+			scroll(0)
+		})
+
+		It("should only render the visible rows", func() {
+			Expect(stats.closes).To(Equal(6))
+			Expect([]int{start, end}).To(Equal([]int{0, 4}))
+		})
+	})
+})
@@ -0,0 +1,251 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A Keyed Component has a Key, which names it in the
+// Path of its Node in place of its index. Keys must be
+// unique amongst the children of a Component; rendering
+// two siblings with the same Key is an error.
+//
+// A child whose Key differs from that of the child it replaces
+// is mounted afresh, rather than reconciled against it.
+type Keyed interface {
+	Component
+	Key() string
+}
+
+// keyOf returns the Key of c, if it is Keyed.
+func keyOf(c Component) string {
+	if k, ok := c.(Keyed); ok {
+		return k.Key()
+	}
+
+	return ""
+}
+
+// A Path identifies a Node by the keys or indexes of the
+// children leading to it from the root, which is named "root".
+// For example, root/0/sidebar is the child keyed "sidebar" of the
+// first child of the root.
+//
+// So that Keys cannot be mistaken for indexes, a Key which is a
+// number, or starts with keyMark, is marked with keyMark in its Path:
+// the child keyed "3" is ~3, not 3. In the String of a Path, '/' and
+// '%' in Keys are escaped as %2F and %25.
+type Path []string
+
+// keyMark marks Keys which could be
+// mistaken for indexes in a Path.
+const keyMark = "~"
+
+var (
+	escaper   = strings.NewReplacer("%", "%25", "/", "%2F")
+	unescaper = strings.NewReplacer("%2F", "/", "%2f", "/", "%25", "%")
+)
+
+func (p Path) String() string {
+	segments := make([]string, len(p))
+	for i, s := range p {
+		segments[i] = escaper.Replace(s)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// ParsePath parses the String() of a Path.
+func ParsePath(s string) (p Path) {
+	for _, segment := range strings.Split(s, "/") {
+		p = append(p, unescaper.Replace(segment))
+	}
+
+	return
+}
+
+// Parent returns the Node this Node is a child of,
+// or nil if this Node is the root.
+func (n *Node) Parent() *Node { return n.parent }
+
+// Depth returns the number of ancestors this Node has.
+func (n *Node) Depth() (depth int) {
+	for p := n.parent; p != nil; p = p.parent {
+		depth++
+	}
+
+	return
+}
+
+// Root returns the root of the tree this Node is in.
+func (n *Node) Root() (root *Node) {
+	for root = n; root.parent != nil; root = root.parent {
+	}
+
+	return
+}
+
+// segment returns this Node's part of its Path.
+func (n *Node) segment() string {
+	if n.parent == nil {
+		return "root"
+	}
+
+	return segmentOf(n.Component, n.index)
+}
+
+// segmentOf returns the part of the Path of the
+// Component c, the child at index i of its parent.
+func segmentOf(c Component, i int) string {
+	k, ok := c.(Keyed)
+	if !ok {
+		return strconv.Itoa(i)
+	}
+
+	key := k.Key()
+	if _, err := strconv.ParseUint(key, 10, 0); err == nil || strings.HasPrefix(key, keyMark) {
+		return keyMark + key
+	}
+
+	return key
+}
+
+// checkKeys returns an error if any two of
+// children have the same Key.
+func checkKeys(children []Component) error {
+	seen := make(map[string]int)
+	for i, c := range children {
+		k, ok := c.(Keyed)
+		if !ok {
+			continue
+		}
+
+		if j, dup := seen[k.Key()]; dup {
+			return fmt.Errorf(
+				"children %d and %d both have the Key %q;"+
+					" the Keys of siblings must be unique",
+				j, i, k.Key(),
+			)
+		}
+
+		seen[k.Key()] = i
+	}
+
+	return nil
+}
+
+// Path returns the Path of this Node from the root.
+func (n *Node) Path() (p Path) {
+	for c := n; c != nil; c = c.parent {
+		p = append(p, c.segment())
+	}
+
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+
+	return
+}
+
+// Lookup returns the Node at Path p, which must start at this Node,
+// or nil if there is none.
+func (n *Node) Lookup(p Path) *Node {
+	if len(p) == 0 || p[0] != n.segment() {
+		return nil
+	}
+
+	if len(p) == 1 {
+		return n
+	}
+
+	for _, child := range n.Children {
+		if child.Component == nil {
+			continue
+		}
+
+		if found := child.Lookup(p[1:]); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// SkipChildren may be returned by the function passed
+// to Walk to skip the children of the Node it was passed.
+var SkipChildren = errors.New("skip children")
+
+// Walk calls visit for this Node and each of its descendants
+// which has a Component, depth first, parents before children.
+//
+// If visit returns SkipChildren, the children of the Node are
+// not visited. If it returns any other error, Walk stops and returns
+// it.
+func (n *Node) Walk(visit func(n *Node) error) (err error) {
+	if n.Component == nil {
+		return
+	}
+
+	switch err = visit(n); err {
+	case SkipChildren:
+		return nil
+	case nil:
+	default:
+		return
+	}
+
+	for _, child := range n.Children {
+		if err = child.Walk(visit); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Find returns the first Node Walk visits for which
+// match returns true, or nil if there is none.
+func (n *Node) Find(match func(n *Node) bool) (found *Node) {
+	n.Walk(func(n *Node) error {
+		if match(n) {
+			found = n
+			return errStop
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// FindAll returns every Node Walk visits for which
+// match returns true.
+func (n *Node) FindAll(match func(n *Node) bool) (found []*Node) {
+	n.Walk(func(n *Node) error {
+		if match(n) {
+			found = append(found, n)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+var errStop = errors.New("stop")
+
+// Named returns a function for Find which
+// matches Nodes whose Component has the given Name().
+func Named(name string) func(*Node) bool {
+	return func(n *Node) bool { return n.Name() == name }
+}
+
+// OfType returns a function for Find which matches Nodes
+// whose Component has the same type as example.
+func OfType(example Component) func(*Node) bool {
+	t := reflect.TypeOf(example)
+	return func(n *Node) bool { return reflect.TypeOf(n.Component) == t }
+}
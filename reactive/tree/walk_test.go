@@ -0,0 +1,171 @@
+package tree_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+)
+
+// keyedComponent is a StaticComponent with a Key.
+type keyedComponent struct {
+	*treetest.StaticComponent
+	key string
+}
+
+func (k keyedComponent) Key() string { return k.key }
+
+var _ = Describe("Walk", func() {
+	var (
+		rec                  treetest.Recorder
+		root, a, b, a_a, a_b *treetest.StaticComponent
+		rootNode             *Node
+		ids                  func(nodes ...*Node) []string
+	)
+
+	ids = func(nodes ...*Node) (ids []string) {
+		for _, n := range nodes {
+			switch c := n.Component.(type) {
+			case *treetest.StaticComponent:
+				ids = append(ids, c.Id)
+			case keyedComponent:
+				ids = append(ids, c.Id)
+			}
+		}
+		return
+	}
+
+	BeforeEach(func() {
+		rec.Clear()
+		root = &treetest.StaticComponent{Id: "root"}
+		a = &treetest.StaticComponent{Id: "a"}
+		b = &treetest.StaticComponent{Id: "b"}
+		a_a = &treetest.StaticComponent{Id: "a_a"}
+		a_b = &treetest.StaticComponent{Id: "a_b"}
+
+		root.Children = []Component{a, nil, b}
+		a.Children = []Component{a_a, keyedComponent{a_b, "sidebar"}}
+
+		rootNode = NewNode(root, &rec)
+	})
+
+	It("should visit every Node, parents first", func() {
+		var visited []*Node
+		Expect(rootNode.Walk(func(n *Node) error {
+			visited = append(visited, n)
+			return nil
+		})).To(Succeed())
+
+		Expect(ids(visited...)).To(Equal([]string{"root", "a", "a_a", "a_b", "b"}))
+	})
+
+	It("should skip children when asked to", func() {
+		var visited []*Node
+		Expect(rootNode.Walk(func(n *Node) error {
+			visited = append(visited, n)
+			if n.Component == Component(a) {
+				return SkipChildren
+			}
+			return nil
+		})).To(Succeed())
+
+		Expect(ids(visited...)).To(Equal([]string{"root", "a", "b"}))
+	})
+
+	It("should find Nodes", func() {
+		Expect(ids(rootNode.Find(Named(a_a.Name())))).To(Equal([]string{"a_a"}))
+		Expect(ids(rootNode.Find(OfType(keyedComponent{})))).To(Equal([]string{"a_b"}))
+		Expect(ids(rootNode.FindAll(OfType(a))...)).To(Equal([]string{"root", "a", "a_a", "b"}))
+		Expect(rootNode.Find(Named("nothing"))).To(BeNil())
+	})
+
+	Describe("a Node", func() {
+		var n *Node
+
+		BeforeEach(func() { n = rootNode.Find(OfType(keyedComponent{})) })
+
+		It("should know its parent", func() {
+			Expect(ids(n.Parent())).To(Equal([]string{"a"}))
+			Expect(n.Parent().Parent()).To(Equal(rootNode))
+			Expect(rootNode.Parent()).To(BeNil())
+			Expect(n.Root()).To(Equal(rootNode))
+		})
+
+		It("should know its depth", func() {
+			Expect(n.Depth()).To(Equal(2))
+			Expect(rootNode.Depth()).To(Equal(0))
+		})
+
+		It("should have a Path", func() {
+			Expect(n.Path().String()).To(Equal("root/0/sidebar"))
+			Expect(rootNode.Find(Named(b.Name())).Path().String()).To(Equal("root/2"))
+		})
+
+		It("should be found by its Path", func() {
+			Expect(rootNode.Lookup(ParsePath("root/0/sidebar"))).To(Equal(n))
+			Expect(rootNode.Lookup(ParsePath("root/1"))).To(BeNil())
+			Expect(rootNode.Lookup(ParsePath("root/0/nothing"))).To(BeNil())
+		})
+	})
+
+	Describe("Keys", func() {
+		var (
+			c, d   *treetest.StaticComponent
+			keyed  *Node
+			render func(children ...Component) *Node
+		)
+
+		// render renders a new tree of children
+		// under the root.
+		render = func(children ...Component) *Node {
+			rec.Clear()
+			return NewNode(&treetest.StaticComponent{Id: "keys", Children: children}, &rec)
+		}
+
+		BeforeEach(func() {
+			c = &treetest.StaticComponent{Id: "c"}
+			d = &treetest.StaticComponent{Id: "d"}
+		})
+
+		for _, key := range []string{"0", "1", "~x", "a/b", "50%", "%2F"} {
+			key := key
+
+			It(fmt.Sprintf("should round-trip %q in a Path", key), func() {
+				keyed = render(keyedComponent{c, key}, d).Find(Named(c.Name()))
+
+				Expect(keyed.Path()).To(HaveLen(2))
+				Expect(ParsePath(keyed.Path().String())).To(Equal(keyed.Path()))
+				Expect(keyed.Root().Lookup(ParsePath(keyed.Path().String()))).To(BeIdenticalTo(keyed))
+			})
+		}
+
+		It("should not be mistaken for indexes", func() {
+			n := render(d, keyedComponent{c, "0"})
+
+			Expect(n.Lookup(ParsePath("root/0"))).To(BeIdenticalTo(n.Find(Named(d.Name()))))
+			Expect(n.Lookup(ParsePath("root/~0"))).To(BeIdenticalTo(n.Find(Named(c.Name()))))
+		})
+
+		It("should mount a child whose Key changes afresh", func() {
+			parent := &treetest.StaticComponent{Id: "keys", Children: []Component{keyedComponent{c, "x"}}}
+			NewNode(parent, &rec)
+
+			parent.Children = []Component{keyedComponent{d, "y"}}
+			Expect(parent.ForceUpdate()).To(Succeed())
+
+			Expect(c.CloseCalls).To(HaveLen(1))
+			Expect(d.MountCalls).To(HaveLen(1))
+			Expect(rec.Errors).To(BeEmpty())
+		})
+
+		It("should be unique amongst siblings", func() {
+			render(keyedComponent{c, "x"}, keyedComponent{d, "x"})
+
+			Expect(rec.Errors).To(HaveLen(1))
+			Expect(rec.Errors[0].Err).To(MatchError(ContainSubstring(`both have the Key "x"`)))
+		})
+	})
+})
@@ -0,0 +1,341 @@
+// Package ansi is a term.Backend which draws to any io.Writer using
+// ANSI (VT100) escape sequences, without depending on a terminal library.
+//
+// Each Flush writes only the cells which have changed since the last,
+// moving the cursor and changing attributes as little as it can.
+// Characters are assumed to each take up one cell.
+//
+// Input is decoded from any io.Reader by a Decoder.
+package ansi // import "zemn.me/term/ansi"
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"zemn.me/term"
+)
+
+var _ term.Backend = &Backend{}
+
+const (
+	altScreen     = "\x1b[?1049h"
+	exitAltScreen = "\x1b[?1049l"
+	hideCursor    = "\x1b[?25l"
+	showCursor    = "\x1b[?25h"
+	clearScreen   = "\x1b[2J"
+	resetSGR      = "\x1b[0m"
+
+	// mouse buttons and motion, SGR mouse
+	// reports, focus and bracketed paste.
+	enableReports  = "\x1b[?1000h\x1b[?1002h\x1b[?1006h\x1b[?1004h\x1b[?2004h"
+	disableReports = "\x1b[?2004l\x1b[?1004l\x1b[?1006l\x1b[?1002l\x1b[?1000l"
+)
+
+// A Backend draws to an io.Writer.
+type Backend struct {
+	w io.Writer
+
+	mu            sync.Mutex
+	width, height int
+
+	// back is drawn to; front is what
+	// was written by the last Flush, or
+	// nil if that is not known.
+	back, front []term.Cell
+
+	// where the terminal's cursor is, and its
+	// attributes, if known.
+	cursorKnown bool
+	x, y        int
+	fg, bg      term.Attribute
+	attrsKnown  bool
+
+	// reporting is whether enableReports was written.
+	reporting bool
+
+	term.EventQueue
+	once sync.Once
+}
+
+// New returns a Backend which draws to w,
+// a terminal of the given size.
+func New(w io.Writer, width, height int) *Backend {
+	return &Backend{
+		w:      w,
+		width:  width,
+		height: height,
+		back:   make([]term.Cell, width*height),
+	}
+}
+
+// Init switches to the alternate screen,
+// clears it and hides the cursor.
+func (b *Backend) Init() (err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.front, b.cursorKnown, b.attrsKnown = nil, false, false
+	_, err = io.WriteString(b.w, altScreen+hideCursor+resetSGR+clearScreen)
+	return
+}
+
+func (b *Backend) Size() (width, height int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.width, b.height
+}
+
+func (b *Backend) CellBuffer() []term.Cell {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.back
+}
+
+// Resize tells the Backend the terminal is now of the given size,
+// sending a ResizeEvent. The next Flush redraws every cell.
+func (b *Backend) Resize(width, height int) {
+	b.mu.Lock()
+	b.width, b.height = width, height
+	b.back = make([]term.Cell, width*height)
+	b.front, b.cursorKnown = nil, false
+	b.mu.Unlock()
+
+	b.Send(term.Event{Type: term.ResizeEvent, Width: width, Height: height})
+}
+
+// Input decodes Events from r, the terminal's input, and sends them
+// on Events until r returns an error or the Backend is closed. Errors
+// other than io.EOF are sent as ErrorEvents.
+//
+// Input also asks the terminal to report the mouse, focus and
+// pastes, which Close undoes.
+func (b *Backend) Input(r io.Reader) (err error) {
+	b.mu.Lock()
+	b.reporting = true
+	_, err = io.WriteString(b.w, enableReports)
+	b.mu.Unlock()
+
+	if err != nil {
+		return
+	}
+
+	d := NewDecoder(r)
+	go func() {
+		<-b.Done()
+		d.Close()
+	}()
+
+	go func() {
+		for {
+			e, err := d.Decode()
+			switch {
+			case err == io.EOF, err == ErrClosed:
+				return
+			case err != nil:
+				b.Send(term.Event{Type: term.ErrorEvent, Err: err})
+				return
+			}
+
+			b.Send(e)
+
+			select {
+			case <-b.Done():
+				return
+			default:
+			}
+		}
+	}()
+
+	return
+}
+
+// Flush writes the cells which have changed since the last Flush.
+func (b *Backend) Flush() (err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out bytes.Buffer
+
+	if b.front == nil {
+		b.front = make([]term.Cell, len(b.back))
+		out.WriteString(resetSGR + clearScreen)
+		b.attrsKnown = true
+		b.fg, b.bg = term.ColorDefault, term.ColorDefault
+
+		// a cleared screen is made of
+		// default spaces; anything else
+		// must be drawn.
+		for i := range b.front {
+			b.front[i] = term.Cell{Ch: ' '}
+		}
+	}
+
+	for i, c := range b.back {
+		if c.Ch == 0 {
+			c.Ch = ' '
+		}
+
+		if c == b.front[i] {
+			continue
+		}
+
+		b.moveTo(&out, i%b.width, i/b.width)
+		b.attrs(&out, c.Fg, c.Bg)
+		out.WriteRune(c.Ch)
+
+		// at the end of a row, terminals differ
+		// in where they leave the cursor.
+		if b.x++; b.x >= b.width {
+			b.cursorKnown = false
+		}
+
+		b.front[i] = c
+	}
+
+	if out.Len() == 0 {
+		return
+	}
+
+	_, err = b.w.Write(out.Bytes())
+	return
+}
+
+// moveTo moves the cursor to x, y by the
+// shortest sequence it knows of.
+func (b *Backend) moveTo(out *bytes.Buffer, x, y int) {
+	if b.cursorKnown && b.x == x && b.y == y {
+		return
+	}
+
+	best := fmt.Sprintf("\x1b[%d;%dH", y+1, x+1)
+
+	if b.cursorKnown {
+		var candidates []string
+
+		switch {
+		case y == b.y && x > b.x:
+			candidates = append(candidates, csi(x-b.x, 'C'))
+		case y == b.y && x < b.x:
+			candidates = append(candidates, csi(b.x-x, 'D'))
+		}
+
+		if y == b.y {
+			candidates = append(candidates, "\r"+csi(x, 'C'))
+		}
+
+		if y == b.y+1 {
+			candidates = append(candidates, "\r\n"+csi(x, 'C'))
+		}
+
+		for _, c := range candidates {
+			if len(c) < len(best) {
+				best = c
+			}
+		}
+	}
+
+	out.WriteString(best)
+	b.x, b.y, b.cursorKnown = x, y, true
+}
+
+// csi returns the sequence moving the cursor n
+// cells in the direction of final, if any.
+func csi(n int, final byte) string {
+	switch n {
+	case 0:
+		return ""
+	case 1:
+		return "\x1b[" + string(final)
+	}
+
+	return "\x1b[" + strconv.Itoa(n) + string(final)
+}
+
+const styles = term.AttrBold | term.AttrUnderline | term.AttrReverse
+
+// attrs changes the attributes of what is written
+// next to fg and bg, changing as little as it can.
+func (b *Backend) attrs(out *bytes.Buffer, fg, bg term.Attribute) {
+	if b.attrsKnown && fg == b.fg && bg == b.bg {
+		return
+	}
+
+	var params []string
+
+	// styles can only be turned off by
+	// resetting everything.
+	reset := !b.attrsKnown || (b.fg|b.bg)&styles&^((fg|bg)&styles) != 0
+	if reset {
+		params = append(params, "0")
+		b.fg, b.bg = term.ColorDefault, term.ColorDefault
+	}
+
+	added := (fg | bg) &^ (b.fg | b.bg) & styles
+	if added&term.AttrBold != 0 {
+		params = append(params, "1")
+	}
+
+	if added&term.AttrUnderline != 0 {
+		params = append(params, "4")
+	}
+
+	if added&term.AttrReverse != 0 {
+		params = append(params, "7")
+	}
+
+	if fg&^styles != b.fg&^styles {
+		params = append(params, colour(fg&^styles, 30))
+	}
+
+	if bg&^styles != b.bg&^styles {
+		params = append(params, colour(bg&^styles, 40))
+	}
+
+	if len(params) > 0 {
+		out.WriteString("\x1b[" + strings.Join(params, ";") + "m")
+	}
+
+	b.fg, b.bg, b.attrsKnown = fg, bg, true
+}
+
+// colour returns the SGR parameter setting the foreground
+// (base 30) or background (base 40) to the colour c.
+func colour(c term.Attribute, base int) string {
+	switch {
+	case c == term.ColorDefault:
+		return strconv.Itoa(base + 9)
+	case c <= term.ColorWhite:
+		return strconv.Itoa(base + int(c-term.ColorBlack))
+	}
+
+	// other colours are of the 256 colour palette,
+	// offset by one as in termbox.
+	return fmt.Sprintf("%d;5;%d", base+8, int(c)-1)
+}
+
+// Close resets the attributes, shows the cursor,
+// leaves the alternate screen and closes the Events
+// channel.
+func (b *Backend) Close() (err error) {
+	b.once.Do(func() {
+		b.EventQueue.Close()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		restore := resetSGR + showCursor + exitAltScreen
+		if b.reporting {
+			restore = disableReports + restore
+		}
+
+		_, err = io.WriteString(b.w, restore)
+	})
+
+	return
+}
@@ -0,0 +1,166 @@
+package ansi_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive/tree/treetest"
+	"zemn.me/term"
+	. "zemn.me/term/ansi"
+)
+
+var _ = Describe("Backend", func() {
+	var (
+		out bytes.Buffer
+		b   *Backend
+	)
+
+	// set sets the cells of row y from x to the
+	// characters of s, with the given attributes.
+	set := func(x, y int, s string, fg, bg term.Attribute) {
+		w, _ := b.Size()
+		cells := b.CellBuffer()
+		for i, r := range []rune(s) {
+			cells[y*w+x+i] = term.Cell{Ch: r, Fg: fg, Bg: bg}
+		}
+	}
+
+	flush := func() string {
+		out.Reset()
+		Expect(b.Flush()).To(Succeed())
+		return out.String()
+	}
+
+	BeforeEach(func() {
+		out.Reset()
+		b = New(&out, 4, 3)
+	})
+
+	It("should enter the alternate screen on Init", func() {
+		Expect(b.Init()).To(Succeed())
+		Expect(out.String()).To(Equal("\x1b[?1049h\x1b[?25l\x1b[0m\x1b[2J"))
+	})
+
+	It("should leave it on Close, closing Events", func() {
+		Expect(b.Close()).To(Succeed())
+		Expect(out.String()).To(Equal("\x1b[0m\x1b[?25h\x1b[?1049l"))
+		Expect(b.Events()).To(BeClosed())
+
+		out.Reset()
+		Expect(b.Close()).To(Succeed())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("should stop reading its input on Close", func() {
+		leaked := treetest.TrackGoroutines()
+
+		r, w := io.Pipe()
+		Expect(b.Input(r)).To(Succeed())
+
+		// read, but never received from Events
+		_, err := w.Write([]byte("a"))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(b.Close()).To(Succeed())
+		w.Close()
+
+		Expect(leaked()).To(Succeed())
+	})
+
+	It("should send Events decoded from its input", func() {
+		Expect(b.Input(strings.NewReader("a\x1b[A"))).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("\x1b[?1006h"))
+
+		Eventually(b.Events()).Should(Receive(Equal(term.Event{Type: term.KeyEvent, Ch: 'a'})))
+		Eventually(b.Events()).Should(Receive(Equal(term.Event{Type: term.KeyEvent, Key: term.KeyUp})))
+
+		out.Reset()
+		Expect(b.Close()).To(Succeed())
+		Expect(out.String()).To(HavePrefix("\x1b[?2004l"))
+	})
+
+	When("flushed for the first time", func() {
+		It("should clear, and draw only what is not blank", func() {
+			set(1, 1, "hi", term.ColorDefault, term.ColorDefault)
+			Expect(flush()).To(Equal("\x1b[0m\x1b[2J\x1b[2;2Hhi"))
+		})
+	})
+
+	When("flushed again", func() {
+		BeforeEach(func() {
+			set(0, 0, "abcd", term.ColorDefault, term.ColorDefault)
+			flush()
+		})
+
+		It("should write nothing if nothing changed", func() {
+			Expect(flush()).To(BeEmpty())
+		})
+
+		It("should write only the changed cells", func() {
+			set(3, 0, "x", term.ColorDefault, term.ColorDefault)
+			Expect(flush()).To(Equal("\x1b[1;4Hx"))
+		})
+
+		It("should not move the cursor between adjacent cells", func() {
+			set(0, 1, "xy", term.ColorDefault, term.ColorDefault)
+			set(3, 1, "z", term.ColorDefault, term.ColorDefault)
+			Expect(flush()).To(Equal("\x1b[2;1Hxy\x1b[Cz"))
+		})
+
+		It("should move relative to the cursor when shorter", func() {
+			set(0, 0, "x", term.ColorDefault, term.ColorDefault)
+			set(0, 1, "y", term.ColorDefault, term.ColorDefault)
+			Expect(flush()).To(Equal("\x1b[1;1Hx\r\ny"))
+		})
+
+		It("should redraw everything after a Resize", func() {
+			go b.Resize(2, 1)
+			Eventually(b.Events()).Should(Receive(Equal(term.Event{
+				Type:   term.ResizeEvent,
+				Width:  2,
+				Height: 1,
+			})))
+
+			set(0, 0, "ab", term.ColorDefault, term.ColorDefault)
+			Expect(flush()).To(Equal("\x1b[0m\x1b[2J\x1b[1;1Hab"))
+		})
+	})
+
+	Describe("attributes", func() {
+		BeforeEach(func() { flush() })
+
+		It("should set colours and styles in one sequence", func() {
+			set(0, 0, "a", term.ColorRed|term.AttrBold, term.ColorBlue)
+			Expect(flush()).To(Equal("\x1b[1;1H\x1b[1;31;44ma"))
+		})
+
+		It("should change only what differs", func() {
+			set(0, 0, "a", term.ColorRed, term.ColorDefault)
+			set(1, 0, "b", term.ColorGreen, term.ColorDefault)
+			set(2, 0, "c", term.ColorGreen|term.AttrUnderline, term.ColorDefault)
+			Expect(flush()).To(Equal("\x1b[1;1H\x1b[31ma\x1b[32mb\x1b[4mc"))
+		})
+
+		It("should reset to turn styles off", func() {
+			set(0, 0, "a", term.ColorRed|term.AttrBold, term.ColorDefault)
+			set(1, 0, "b", term.ColorRed, term.ColorDefault)
+			Expect(flush()).To(Equal("\x1b[1;1H\x1b[1;31ma\x1b[0;31mb"))
+		})
+
+		It("should use the 256 colour palette beyond white", func() {
+			set(0, 0, "a", 197, term.ColorDefault)
+			Expect(flush()).To(Equal("\x1b[1;1H\x1b[38;5;196ma"))
+		})
+
+		It("should restore defaults", func() {
+			set(0, 0, "a", term.ColorRed, term.ColorDefault)
+			flush()
+			set(0, 0, " ", term.ColorDefault, term.ColorDefault)
+			Expect(flush()).To(Equal("\r\x1b[39m "))
+		})
+	})
+})
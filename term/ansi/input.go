@@ -0,0 +1,470 @@
+package ansi
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"zemn.me/term"
+)
+
+// DefaultEscapeTimeout is how long a Decoder waits by default
+// after an escape for the rest of a sequence before deciding
+// the escape key was pressed.
+const DefaultEscapeTimeout = 50 * time.Millisecond
+
+const (
+	pasteStart = "\x1b[200~"
+	pasteEnd   = "\x1b[201~"
+)
+
+// A Decoder decodes the input of a terminal in raw mode into Events.
+//
+// It understands keys with xterm style modifiers, function keys,
+// SGR and X10 mouse reports, bracketed paste and focus reports.
+// Sequences it doesn't understand are skipped.
+type Decoder struct {
+	// EscapeTimeout is how long to wait after an escape
+	// for the rest of a sequence. If it is zero,
+	// DefaultEscapeTimeout is used.
+	EscapeTimeout time.Duration
+
+	r      io.Reader
+	chunks chan chunk
+
+	done   chan struct{}
+	closed sync.Once
+
+	buf []byte
+	err error
+}
+
+// ErrClosed is returned by Decode once the Decoder is closed.
+var ErrClosed = errors.New("ansi: Decoder closed")
+
+type chunk struct {
+	b   []byte
+	err error
+}
+
+// NewDecoder returns a Decoder reading from r.
+//
+// A Decoder reads from r in its own goroutine, which returns
+// once r returns an error, or once the Decoder is closed and
+// the Read in progress returns.
+func NewDecoder(r io.Reader) *Decoder { return &Decoder{r: r, done: make(chan struct{})} }
+
+func (d *Decoder) read() {
+	for {
+		b := make([]byte, 256)
+		n, err := d.r.Read(b)
+
+		select {
+		case d.chunks <- chunk{b[:n], err}:
+		case <-d.done:
+			return
+		}
+
+		if err != nil {
+			close(d.chunks)
+			return
+		}
+	}
+}
+
+// Close closes the Decoder, so that Decode returns ErrClosed,
+// and what is read from the io.Reader afterwards is dropped.
+// Closing it again does nothing.
+func (d *Decoder) Close() error {
+	d.closed.Do(func() { close(d.done) })
+	return nil
+}
+
+// Decode returns the next Event. Once everything read
+// before an error from the io.Reader has been decoded,
+// it returns that error.
+func (d *Decoder) Decode() (e term.Event, err error) {
+	if d.chunks == nil {
+		d.chunks = make(chan chunk)
+		go d.read()
+	}
+
+	timeout := d.EscapeTimeout
+	if timeout == 0 {
+		timeout = DefaultEscapeTimeout
+	}
+
+	// expired is whether the rest of a sequence
+	// has been waited for; if so, what's buffered
+	// is decoded as it is.
+	var expired bool
+
+	for {
+		if len(d.buf) > 0 {
+			e, n, ok := decode(d.buf, expired || d.err != nil)
+			d.buf = d.buf[n:]
+
+			switch {
+			case ok:
+				return e, nil
+			case n > 0:
+				// skipped something unknown
+				expired = false
+				continue
+			}
+		}
+
+		if d.err != nil {
+			return e, d.err
+		}
+
+		// pastes can take a while to
+		// arrive, and are never keys.
+		var timer *time.Timer
+		var expire <-chan time.Time
+		if len(d.buf) > 0 && !bytes.HasPrefix(d.buf, []byte(pasteStart)) {
+			timer = time.NewTimer(timeout)
+			expire = timer.C
+		}
+
+		select {
+		case c := <-d.chunks:
+			d.buf = append(d.buf, c.b...)
+			d.err = c.err
+		case <-expire:
+			expired = true
+		case <-d.done:
+			return e, ErrClosed
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// decode decodes the Event at the start of b, returning how many bytes
+// it took up. If ok is false, those bytes were not understood and
+// should be skipped, or if none, more are needed. If final is true,
+// no more are coming and what b holds must be decoded as it is.
+func decode(b []byte, final bool) (e term.Event, n int, ok bool) {
+	e.Type = term.KeyEvent
+
+	switch c := b[0]; {
+	case c == 0x1b:
+		return decodeEscape(b, final)
+	case c == '\r' || c == '\n':
+		e.Key = term.KeyEnter
+	case c == '\t':
+		e.Key = term.KeyTab
+	case c == 0x7f || c == 0x08:
+		e.Key = term.KeyBackspace
+	case c == 0:
+		e.Ch, e.Mod = ' ', term.ModCtrl
+	case c <= 0x1a:
+		e.Ch, e.Mod = 'a'+rune(c)-1, term.ModCtrl
+	case c < ' ':
+		// ctrl-\, ctrl-], ctrl-^ and ctrl-_
+		e.Ch, e.Mod = rune(c)+'@', term.ModCtrl
+	default:
+		if !final && !utf8.FullRune(b) {
+			return e, 0, false
+		}
+
+		e.Ch, n = utf8.DecodeRune(b)
+		return e, n, true
+	}
+
+	return e, 1, true
+}
+
+func decodeEscape(b []byte, final bool) (e term.Event, n int, ok bool) {
+	e.Type = term.KeyEvent
+
+	if len(b) == 1 {
+		if !final {
+			return e, 0, false
+		}
+
+		e.Key = term.KeyEsc
+		return e, 1, true
+	}
+
+	switch b[1] {
+	case '[':
+		if e, n, ok = decodeCSI(b, final); n > 0 || !final {
+			return
+		}
+	case 'O':
+		if e, n, ok = decodeSS3(b, final); n > 0 || !final {
+			return
+		}
+	}
+
+	// anything else after an escape is
+	// itself pressed with alt held.
+	e, n, ok = decode(b[1:], final)
+	switch {
+	case n == 0 && !final:
+		return e, 0, false
+	case !ok || e.Type != term.KeyEvent || e.Mod&term.ModAlt != 0:
+		e = term.Event{Type: term.KeyEvent, Key: term.KeyEsc}
+		return e, 1, true
+	}
+
+	e.Mod |= term.ModAlt
+	return e, n + 1, true
+}
+
+// ss3Keys are the keys of SS3 sequences, and
+// CSI sequences without parameters before the
+// final byte.
+var ss3Keys = map[byte]term.Key{
+	'A': term.KeyUp,
+	'B': term.KeyDown,
+	'C': term.KeyRight,
+	'D': term.KeyLeft,
+	'H': term.KeyHome,
+	'F': term.KeyEnd,
+	'P': term.KeyF1,
+	'Q': term.KeyF2,
+	'R': term.KeyF3,
+	'S': term.KeyF4,
+}
+
+// decodeSS3 decodes sequences starting ESC O, sent
+// for some keys in application mode. If the sequence
+// is incomplete and final, n is zero.
+func decodeSS3(b []byte, final bool) (e term.Event, n int, ok bool) {
+	e.Type = term.KeyEvent
+
+	if len(b) < 3 {
+		return
+	}
+
+	key, known := ss3Keys[b[2]]
+	if !known {
+		return e, 3, false
+	}
+
+	e.Key = key
+	return e, 3, true
+}
+
+// tildeKeys are the keys of CSI sequences
+// ending in ~, by their first parameter.
+var tildeKeys = map[int]term.Key{
+	1:  term.KeyHome,
+	2:  term.KeyInsert,
+	3:  term.KeyDelete,
+	4:  term.KeyEnd,
+	5:  term.KeyPgUp,
+	6:  term.KeyPgDn,
+	7:  term.KeyHome,
+	8:  term.KeyEnd,
+	11: term.KeyF1,
+	12: term.KeyF2,
+	13: term.KeyF3,
+	14: term.KeyF4,
+	15: term.KeyF5,
+	17: term.KeyF6,
+	18: term.KeyF7,
+	19: term.KeyF8,
+	20: term.KeyF9,
+	21: term.KeyF10,
+	23: term.KeyF11,
+	24: term.KeyF12,
+}
+
+// decodeCSI decodes sequences starting ESC [. If the
+// sequence is incomplete and final, n is zero.
+func decodeCSI(b []byte, final bool) (e term.Event, n int, ok bool) {
+	e.Type = term.KeyEvent
+
+	// X10 mouse reports are not CSI sequences
+	// proper: three bytes follow the M.
+	if len(b) >= 3 && b[2] == 'M' {
+		if len(b) < 6 {
+			return
+		}
+
+		e = mouse(int(b[3])-32, int(b[4])-33, int(b[5])-33, false)
+		return e, 6, true
+	}
+
+	if bytes.HasPrefix(b, []byte(pasteStart)) {
+		end := bytes.Index(b, []byte(pasteEnd))
+		switch {
+		case end >= 0:
+			n = end + len(pasteEnd)
+		case final:
+			end, n = len(b), len(b)
+		default:
+			return e, 0, false
+		}
+
+		e.Type, e.Text = term.PasteEvent, string(b[len(pasteStart):end])
+		return e, n, true
+	}
+
+	// parameter and intermediate bytes
+	// come before a final byte.
+	end := 2
+	for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+		if b[end] < 0x20 {
+			// not part of a sequence; skip
+			// what came before it.
+			return e, end, false
+		}
+
+		end++
+	}
+
+	if end == len(b) {
+		return
+	}
+
+	n = end + 1
+	params, cmd := string(b[2:end]), b[end]
+
+	if strings.HasPrefix(params, "<") && (cmd == 'M' || cmd == 'm') {
+		p := numbers(params[1:])
+		if len(p) != 3 {
+			return e, n, false
+		}
+
+		return mouse(p[0], p[1]-1, p[2]-1, cmd == 'm'), n, true
+	}
+
+	p := numbers(params)
+	if p == nil && params != "" {
+		return e, n, false
+	}
+
+	switch cmd {
+	case 'I', 'O':
+		if params != "" {
+			return e, n, false
+		}
+
+		e.Type, e.Focused = term.FocusEvent, cmd == 'I'
+		return e, n, true
+	case 'Z':
+		e.Key, e.Mod = term.KeyTab, term.ModShift
+		return e, n, true
+	case '~':
+		if len(p) == 0 {
+			return e, n, false
+		}
+
+		key, known := tildeKeys[p[0]]
+		if !known {
+			return e, n, false
+		}
+
+		e.Key = key
+	default:
+		key, known := ss3Keys[cmd]
+		if !known {
+			return e, n, false
+		}
+
+		e.Key = key
+	}
+
+	if len(p) > 1 {
+		e.Mod = modifiers(p[1])
+	}
+
+	return e, n, true
+}
+
+// numbers parses parameters separated by ';',
+// returning nil if they are not all numbers.
+// Empty parameters are 1.
+func numbers(params string) (p []int) {
+	if params == "" {
+		return
+	}
+
+	for _, s := range strings.Split(params, ";") {
+		if s == "" {
+			p = append(p, 1)
+			continue
+		}
+
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return nil
+		}
+
+		p = append(p, v)
+	}
+
+	return
+}
+
+// modifiers decodes an xterm modifier parameter,
+// which is one more than a bit set of shift, alt,
+// ctrl and meta. Meta is taken to be alt.
+func modifiers(param int) (m term.Modifier) {
+	bits := param - 1
+	if bits&1 != 0 {
+		m |= term.ModShift
+	}
+
+	if bits&(2|8) != 0 {
+		m |= term.ModAlt
+	}
+
+	if bits&4 != 0 {
+		m |= term.ModCtrl
+	}
+
+	return
+}
+
+// mouse decodes a mouse report of the button code cb
+// at the zero based x and y. If release is true, it
+// is an SGR report of a button being released.
+func mouse(cb, x, y int, release bool) (e term.Event) {
+	e.Type = term.MouseEvent
+	e.Mouse = image.Pt(x, y)
+
+	if cb&4 != 0 {
+		e.Mod |= term.ModShift
+	}
+
+	if cb&8 != 0 {
+		e.Mod |= term.ModAlt
+	}
+
+	if cb&16 != 0 {
+		e.Mod |= term.ModCtrl
+	}
+
+	if cb&32 != 0 {
+		e.Mod |= term.ModMotion
+	}
+
+	switch button := cb & 3; {
+	case cb&64 != 0:
+		e.Button = term.MouseWheelUp + term.Button(button&1)
+	case release:
+		e.Button = term.MouseRelease
+	case button == 3 && e.Mod&term.ModMotion != 0:
+		e.Button = term.MouseNone
+	case button == 3:
+		e.Button = term.MouseRelease
+	default:
+		e.Button = term.MouseLeft + term.Button(button)
+	}
+
+	return
+}
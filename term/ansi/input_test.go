@@ -0,0 +1,223 @@
+package ansi_test
+
+import (
+	"errors"
+	"image"
+	"io"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/term"
+	. "zemn.me/term/ansi"
+)
+
+// decodeAll decodes every Event in s.
+func decodeAll(s string) (events []term.Event) {
+	d := NewDecoder(strings.NewReader(s))
+	for {
+		e, err := d.Decode()
+		if err == io.EOF {
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		events = append(events, e)
+	}
+}
+
+func key(k term.Key, mod term.Modifier) term.Event {
+	return term.Event{Type: term.KeyEvent, Key: k, Mod: mod}
+}
+
+func char(ch rune, mod term.Modifier) term.Event {
+	return term.Event{Type: term.KeyEvent, Ch: ch, Mod: mod}
+}
+
+func click(b term.Button, x, y int, mod term.Modifier) term.Event {
+	return term.Event{Type: term.MouseEvent, Button: b, Mouse: image.Pt(x, y), Mod: mod}
+}
+
+// corpus is input as sent by real terminals
+// (xterm, unless noted), and what it decodes to.
+var corpus = []struct {
+	name, in string
+	want     []term.Event
+}{
+	{"plain text", "hi", []term.Event{char('h', 0), char('i', 0)}},
+	{"utf-8", "é世", []term.Event{char('é', 0), char('世', 0)}},
+	{"invalid utf-8", "\xff", []term.Event{char('�', 0)}},
+	{"enter", "\r", []term.Event{key(term.KeyEnter, 0)}},
+	{"tab", "\t", []term.Event{key(term.KeyTab, 0)}},
+	{"shift-tab", "\x1b[Z", []term.Event{key(term.KeyTab, term.ModShift)}},
+	{"backspace", "\x7f", []term.Event{key(term.KeyBackspace, 0)}},
+	{"ctrl-h backspace", "\x08", []term.Event{key(term.KeyBackspace, 0)}},
+	{"ctrl-c", "\x03", []term.Event{char('c', term.ModCtrl)}},
+	{"ctrl-space", "\x00", []term.Event{char(' ', term.ModCtrl)}},
+	{"ctrl-backslash", "\x1c", []term.Event{char('\\', term.ModCtrl)}},
+	{"alt-a", "\x1ba", []term.Event{char('a', term.ModAlt)}},
+	{"alt-ctrl-a", "\x1b\x01", []term.Event{char('a', term.ModAlt|term.ModCtrl)}},
+	{"alt-[", "\x1b[", []term.Event{char('[', term.ModAlt)}},
+	{"alt-O", "\x1bO", []term.Event{char('O', term.ModAlt)}},
+	{"escape", "\x1b", []term.Event{key(term.KeyEsc, 0)}},
+	{"alt-escape", "\x1b\x1b", []term.Event{key(term.KeyEsc, term.ModAlt)}},
+	{"alt-up, as sent by rxvt", "\x1b\x1b[A", []term.Event{key(term.KeyUp, term.ModAlt)}},
+
+	{"up", "\x1b[A", []term.Event{key(term.KeyUp, 0)}},
+	{"down", "\x1b[B", []term.Event{key(term.KeyDown, 0)}},
+	{"right", "\x1b[C", []term.Event{key(term.KeyRight, 0)}},
+	{"left", "\x1b[D", []term.Event{key(term.KeyLeft, 0)}},
+	{"home", "\x1b[H", []term.Event{key(term.KeyHome, 0)}},
+	{"end", "\x1b[F", []term.Event{key(term.KeyEnd, 0)}},
+	{"application up", "\x1bOA", []term.Event{key(term.KeyUp, 0)}},
+	{"shift-up", "\x1b[1;2A", []term.Event{key(term.KeyUp, term.ModShift)}},
+	{"alt-left", "\x1b[1;3D", []term.Event{key(term.KeyLeft, term.ModAlt)}},
+	{"ctrl-right", "\x1b[1;5C", []term.Event{key(term.KeyRight, term.ModCtrl)}},
+	{"ctrl-shift-home", "\x1b[1;6H", []term.Event{key(term.KeyHome, term.ModCtrl|term.ModShift)}},
+	{"meta-down", "\x1b[1;9B", []term.Event{key(term.KeyDown, term.ModAlt)}},
+	{"home, as sent by the linux console", "\x1b[1~", []term.Event{key(term.KeyHome, 0)}},
+	{"end, as sent by rxvt", "\x1b[8~", []term.Event{key(term.KeyEnd, 0)}},
+	{"insert", "\x1b[2~", []term.Event{key(term.KeyInsert, 0)}},
+	{"delete", "\x1b[3~", []term.Event{key(term.KeyDelete, 0)}},
+	{"ctrl-delete", "\x1b[3;5~", []term.Event{key(term.KeyDelete, term.ModCtrl)}},
+	{"page up", "\x1b[5~", []term.Event{key(term.KeyPgUp, 0)}},
+	{"page down", "\x1b[6~", []term.Event{key(term.KeyPgDn, 0)}},
+
+	{"f1", "\x1bOP", []term.Event{key(term.KeyF1, 0)}},
+	{"f4", "\x1bOS", []term.Event{key(term.KeyF4, 0)}},
+	{"shift-f1", "\x1b[1;2P", []term.Event{key(term.KeyF1, term.ModShift)}},
+	{"f1, as sent by rxvt", "\x1b[11~", []term.Event{key(term.KeyF1, 0)}},
+	{"f5", "\x1b[15~", []term.Event{key(term.KeyF5, 0)}},
+	{"f6", "\x1b[17~", []term.Event{key(term.KeyF6, 0)}},
+	{"f10", "\x1b[21~", []term.Event{key(term.KeyF10, 0)}},
+	{"f11", "\x1b[23~", []term.Event{key(term.KeyF11, 0)}},
+	{"ctrl-f12", "\x1b[24;5~", []term.Event{key(term.KeyF12, term.ModCtrl)}},
+
+	{"sgr left press", "\x1b[<0;10;5M", []term.Event{click(term.MouseLeft, 9, 4, 0)}},
+	{"sgr left release", "\x1b[<0;10;5m", []term.Event{click(term.MouseRelease, 9, 4, 0)}},
+	{"sgr middle press", "\x1b[<1;1;1M", []term.Event{click(term.MouseMiddle, 0, 0, 0)}},
+	{"sgr ctrl-right press", "\x1b[<18;3;4M", []term.Event{click(term.MouseRight, 2, 3, term.ModCtrl)}},
+	{"sgr drag", "\x1b[<32;7;8M", []term.Event{click(term.MouseLeft, 6, 7, term.ModMotion)}},
+	{"sgr motion", "\x1b[<35;7;8M", []term.Event{click(term.MouseNone, 6, 7, term.ModMotion)}},
+	{"sgr wheel up", "\x1b[<64;2;2M", []term.Event{click(term.MouseWheelUp, 1, 1, 0)}},
+	{"sgr wheel down", "\x1b[<65;2;2M", []term.Event{click(term.MouseWheelDown, 1, 1, 0)}},
+	{"sgr beyond 223", "\x1b[<0;300;2M", []term.Event{click(term.MouseLeft, 299, 1, 0)}},
+	{"x10 left press", "\x1b[M *%", []term.Event{click(term.MouseLeft, 9, 4, 0)}},
+	{"x10 release", "\x1b[M#!!", []term.Event{click(term.MouseRelease, 0, 0, 0)}},
+	{"x10 shift-wheel down", "\x1b[Me!!", []term.Event{click(term.MouseWheelDown, 0, 0, term.ModShift)}},
+
+	{"paste", "\x1b[200~hello\r\x1b[Aworld\x1b[201~", []term.Event{
+		{Type: term.PasteEvent, Text: "hello\r\x1b[Aworld"},
+	}},
+	{"empty paste", "\x1b[200~\x1b[201~", []term.Event{{Type: term.PasteEvent}}},
+	{"unterminated paste", "\x1b[200~abc", []term.Event{{Type: term.PasteEvent, Text: "abc"}}},
+	{"focus in", "\x1b[I", []term.Event{{Type: term.FocusEvent, Focused: true}}},
+	{"focus out", "\x1b[O", []term.Event{{Type: term.FocusEvent}}},
+
+	{"keys in a row", "a\x1b[Ab\x1bOPc", []term.Event{
+		char('a', 0), key(term.KeyUp, 0), char('b', 0), key(term.KeyF1, 0), char('c', 0),
+	}},
+	{"unknown sequences are skipped", "\x1b[?1;2c\x1b[99~\x1bOXa", []term.Event{char('a', 0)}},
+	{"a broken sequence is skipped up to the control character", "\x1b[12\rx", []term.Event{
+		key(term.KeyEnter, 0), char('x', 0),
+	}},
+}
+
+var _ = Describe("Decoder", func() {
+	for _, c := range corpus {
+		c := c
+
+		It("should decode "+c.name, func() {
+			Expect(decodeAll(c.in)).To(Equal(c.want))
+		})
+	}
+
+	It("should return the error of the io.Reader after what was read", func() {
+		failed := errors.New("failed")
+		d := NewDecoder(io.MultiReader(strings.NewReader("a"), errReader{failed}))
+
+		Expect(d.Decode()).To(Equal(char('a', 0)))
+		_, err := d.Decode()
+		Expect(err).To(Equal(failed))
+	})
+
+	It("should stop once closed", func() {
+		r, w := io.Pipe()
+		defer w.Close()
+
+		d := NewDecoder(r)
+		Expect(d.Close()).To(Succeed())
+		Expect(d.Close()).To(Succeed())
+
+		_, err := d.Decode()
+		Expect(err).To(Equal(ErrClosed))
+	})
+
+	Describe("escape timeout", func() {
+		var (
+			w *io.PipeWriter
+			d *Decoder
+		)
+
+		BeforeEach(func() {
+			var r *io.PipeReader
+			r, w = io.Pipe()
+			d = NewDecoder(r)
+		})
+
+		AfterEach(func() { w.Close() })
+
+		decode := func() <-chan term.Event {
+			events := make(chan term.Event, 1)
+			go func() {
+				e, err := d.Decode()
+				if err == nil {
+					events <- e
+				}
+			}()
+
+			return events
+		}
+
+		It("should decode an escape alone once it has passed", func() {
+			d.EscapeTimeout = 10 * time.Millisecond
+			events := decode()
+
+			w.Write([]byte("\x1b"))
+			Eventually(events).Should(Receive(Equal(key(term.KeyEsc, 0))))
+
+			events = decode()
+			w.Write([]byte("["))
+			Eventually(events).Should(Receive(Equal(char('[', 0))))
+		})
+
+		It("should wait for the rest of a sequence until then", func() {
+			d.EscapeTimeout = time.Hour
+			events := decode()
+
+			w.Write([]byte("\x1b"))
+			w.Write([]byte("[1;5"))
+			Consistently(events).ShouldNot(Receive())
+
+			w.Write([]byte("A"))
+			Eventually(events).Should(Receive(Equal(key(term.KeyUp, term.ModCtrl))))
+		})
+
+		It("should not time out during a paste", func() {
+			d.EscapeTimeout = time.Millisecond
+			events := decode()
+
+			w.Write([]byte("\x1b[200~ab"))
+			Consistently(events).ShouldNot(Receive())
+
+			w.Write([]byte("c\x1b[201~"))
+			Eventually(events).Should(Receive(Equal(term.Event{Type: term.PasteEvent, Text: "abc"})))
+		})
+	})
+})
+
+type errReader struct{ err error }
+
+func (e errReader) Read([]byte) (int, error) { return 0, e.err }
@@ -0,0 +1,52 @@
+package term
+
+// A Backend is a terminal a Term draws to
+// and receives Events from.
+type Backend interface {
+	// Init prepares the terminal for drawing.
+	Init() error
+
+	Size() (width, height int)
+
+	// CellBuffer returns the cells to draw to, row by row,
+	// reallocated if the terminal has changed size. They are
+	// shown when Flush is called.
+	CellBuffer() []Cell
+	Flush() error
+
+	// Events returns the channel Events are sent on,
+	// which is closed when the Backend is.
+	Events() <-chan Event
+
+	// Close restores the terminal.
+	Close() error
+}
+
+type Attribute uint16
+
+// Colours. They can be combined with
+// the attributes below using '|'.
+const (
+	ColorDefault Attribute = iota
+	ColorBlack
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+const (
+	AttrBold Attribute = 1 << (iota + 9)
+	AttrUnderline
+	AttrReverse
+)
+
+// A Cell is one character on the screen,
+// with foreground and background attributes.
+type Cell struct {
+	Ch     rune
+	Fg, Bg Attribute
+}
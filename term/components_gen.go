@@ -0,0 +1,64 @@
+// Code generated by componentgen; DO NOT EDIT.
+
+package term
+
+import "zemn.me/reactive/tree"
+
+var _ tree.Component = LoadingBar{}
+
+func (LoadingBar) Name() string               { return "LoadingBar" }
+func (LoadingBar) Mount(tree.StateController) {}
+func (LoadingBar) Close()                     {}
+
+func (c LoadingBar) ShouldUpdate(old tree.Component) (bool, error) {
+	o, ok := old.(LoadingBar)
+	if !ok {
+		return true, nil
+	}
+
+	if (c.Canvas == nil) != (o.Canvas == nil) || c.Canvas != nil && c.Canvas.ShouldUpdate(o.Canvas) {
+		return true, nil
+	}
+
+	return c.Fill != o.Fill ||
+		c.Empty != o.Empty ||
+		c.Progress != o.Progress, nil
+}
+
+var _ tree.Component = Fill{}
+
+func (Fill) Name() string               { return "fill" }
+func (Fill) Mount(tree.StateController) {}
+func (Fill) Close()                     {}
+
+func (c Fill) ShouldUpdate(old tree.Component) (bool, error) {
+	o, ok := old.(Fill)
+	if !ok {
+		return true, nil
+	}
+
+	if (c.Canvas == nil) != (o.Canvas == nil) || c.Canvas != nil && c.Canvas.ShouldUpdate(o.Canvas) {
+		return true, nil
+	}
+
+	return c.Cell != o.Cell, nil
+}
+
+var _ tree.Component = Text{}
+
+func (Text) Name() string               { return "text" }
+func (Text) Mount(tree.StateController) {}
+func (Text) Close()                     {}
+
+func (c Text) ShouldUpdate(old tree.Component) (bool, error) {
+	o, ok := old.(Text)
+	if !ok {
+		return true, nil
+	}
+
+	if (c.Canvas == nil) != (o.Canvas == nil) || c.Canvas != nil && c.Canvas.ShouldUpdate(o.Canvas) {
+		return true, nil
+	}
+
+	return c.Text != o.Text, nil
+}
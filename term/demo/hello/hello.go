@@ -1,13 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"image"
+	"sync"
 	"time"
 
-	"github.com/nsf/termbox-go"
-
-	"zemn.me/reactive"
+	"zemn.me/reactive/clock"
 	"zemn.me/reactive/tree"
 	"zemn.me/term"
 )
@@ -22,11 +22,26 @@ var _ tree.Component = &FakeProcess{}
 
 type FakeProcess struct {
 	term.LoadingBar
-	done chan bool
+	Clock clock.Clock
+
+	// the timer of the next step,
+	// guarded by mu
+	mu    *sync.Mutex
+	timer clock.Timer
 }
 
 func (FakeProcess) Name() string { return "fakeprocess" }
-func (f FakeProcess) Close()     { close(f.done) }
+func (f *FakeProcess) Close() {
+	// never mounted
+	if f.mu == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.timer.Stop()
+}
 func (f FakeProcess) ShouldUpdate(old tree.Component) (bool, error) {
 	return f != *old.(*FakeProcess), nil
 }
@@ -57,51 +72,73 @@ func (f FakeProcess) Render() (components []tree.Component, err error) {
 }
 
 func (f *FakeProcess) Mount(s tree.StateController) {
-	go func() {
-		for {
-			select {
-			case <-f.done:
-				return
-			case <-time.After(10 * time.Millisecond):
-				f.LoadingBar.Progress = (f.LoadingBar.Progress + 0.01)
-				if f.LoadingBar.Progress > 1 {
-					f.LoadingBar.Progress = 0
-				}
-				s.Update()
-			}
+	f.mu = new(sync.Mutex)
+
+	var step func()
+	step = func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.LoadingBar.Progress = (f.LoadingBar.Progress + 0.01)
+		if f.LoadingBar.Progress > 1 {
+			f.LoadingBar.Progress = 0
 		}
-	}()
+		s.Update()
+
+		f.timer = f.Clock.AfterFunc(10*time.Millisecond, step)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.timer = f.Clock.AfterFunc(10*time.Millisecond, step)
 }
 
-func do() (err error) {
-	term, err := term.New(func(c term.Canvas) (components []tree.Component, err error) {
+// newTerm returns the Term of the demo, drawing to b,
+// whose FakeProcess is stepped by c.
+func newTerm(b term.Backend, c clock.Clock) (*term.Term, error) {
+	return term.New(b, func(canvas term.Canvas) (components []tree.Component, err error) {
 		return []tree.Component{
 			&FakeProcess{
 				LoadingBar: term.LoadingBar{
-					Canvas:   c,
+					Canvas:   canvas,
 					Empty:    ' ',
 					Fill:     '#',
 					Progress: .7,
 				},
+				Clock: c,
 			},
 		}, nil
 	})
-
-	defer termbox.Close()
-	reactive.Render(term, mapper{})
-	<-time.After(10 * time.Second)
-	return nil
 }
 
-type mapper struct{}
+func do() (err error) {
+	b := &term.Termbox{}
+	t, err := newTerm(b, clock.Real)
+	if err != nil {
+		return
+	}
 
-func (mapper) Map(tree.Component) {
-	if err := termbox.Flush(); err != nil {
-		panic(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	s := tree.NewScheduler()
+	n := s.NewNode(t, mapper{b})
+	defer n.Unmount()
+
+	if err = s.Run(ctx); err == context.DeadlineExceeded {
+		err = nil
 	}
+
+	return
 }
-func (mapper) UnMap(tree.Component) {
-	if err := termbox.Flush(); err != nil {
+
+type mapper struct{ term.Backend }
+
+func (mapper) Map(tree.Component)   {}
+func (mapper) UnMap(tree.Component) {}
+func (m mapper) Commit() {
+	if err := m.Flush(); err != nil {
 		panic(err)
 	}
 }
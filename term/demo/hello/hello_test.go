@@ -0,0 +1,51 @@
+package main
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive/tree"
+	"zemn.me/reactive/tree/treetest"
+	"zemn.me/term/termtest"
+)
+
+var _ = Describe("the demo", func() {
+	var (
+		screen *termtest.Screen
+		s      *treetest.Scheduler
+		n      *tree.Node
+	)
+
+	BeforeEach(func() {
+		screen = termtest.NewScreen(20, 5)
+		s = treetest.NewScheduler()
+
+		t, err := newTerm(screen, s.Clock)
+		Expect(err).ToNot(HaveOccurred())
+
+		n = s.NewNode(t, screen)
+		s.Flush()
+	})
+
+	It("should make progress as time passes", func() {
+		Expect(screen.Frame().Line(0)).To(HavePrefix("70/100"))
+
+		s.Advance(50 * time.Millisecond)
+		s.Flush()
+
+		Expect(screen.Frame().Line(0)).To(HavePrefix("75/100"))
+		Expect(screen.Errors()).To(BeEmpty())
+	})
+
+	It("should mount and unmount", func() {
+		s.Advance(50 * time.Millisecond)
+		s.Flush()
+
+		Expect(n.Unmount).ToNot(Panic())
+		Expect(screen.Closed()).To(BeTrue())
+		Expect(s.Waiting()).To(Equal(0))
+		Expect(screen.Errors()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,109 @@
+package term
+
+import "image"
+
+type EventType int
+
+const (
+	KeyEvent EventType = iota
+	ResizeEvent
+	MouseEvent
+	ErrorEvent
+
+	// PasteEvent is text pasted into a terminal
+	// with bracketed paste turned on.
+	PasteEvent
+
+	// FocusEvent is a terminal gaining or
+	// losing focus, if it reports it.
+	FocusEvent
+)
+
+// A Key is a key pressed. Keys which produce
+// characters are KeyRune, with the character
+// in Event.Ch.
+type Key uint16
+
+const (
+	KeyRune Key = iota
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEsc
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDn
+	KeyInsert
+	KeyDelete
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+)
+
+// Modifiers are the modifier keys held, combined with '|'.
+type Modifier uint8
+
+const (
+	ModShift Modifier = 1 << iota
+	ModAlt
+	ModCtrl
+
+	// ModMotion is set on MouseEvents
+	// caused by the mouse moving.
+	ModMotion
+)
+
+type Button uint8
+
+const (
+	MouseNone Button = iota
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseRelease
+	MouseWheelUp
+	MouseWheelDown
+)
+
+// An Event is something that happened to a terminal.
+// Which fields are set depends on Type.
+type Event struct {
+	Type EventType
+
+	// KeyEvent
+	Key Key
+	Ch  rune
+
+	// KeyEvent and MouseEvent
+	Mod Modifier
+
+	// ResizeEvent
+	Width, Height int
+
+	// MouseEvent
+	Mouse  image.Point
+	Button Button
+
+	// ErrorEvent
+	Err error
+
+	// PasteEvent
+	Text string
+
+	// FocusEvent
+	Focused bool
+}
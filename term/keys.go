@@ -0,0 +1,41 @@
+package term
+
+import "zemn.me/reactive/tree"
+
+// A KeyHandler is a Component which handles KeyEvents.
+//
+// A KeyEvent is given first to the Component with focus, then to
+// each of its ancestors in turn until one handles it. A Component
+// can take focus through the tree.Focuser it is Mounted with. Tab
+// and Shift-Tab, if not handled, move focus between KeyHandlers.
+type KeyHandler interface {
+	tree.Component
+
+	// HandleKey reports whether the
+	// KeyEvent e was handled.
+	HandleKey(e Event) (handled bool)
+}
+
+func isKeyHandler(n *tree.Node) bool {
+	_, ok := n.Component.(KeyHandler)
+	return ok
+}
+
+// key delivers the KeyEvent e within the tree of n.
+func key(n inputTree, e Event) {
+	handled := n.Bubble(func(n *tree.Node) bool {
+		h, ok := n.Component.(KeyHandler)
+		return ok && h.HandleKey(e)
+	})
+
+	if handled || e.Key != KeyTab {
+		return
+	}
+
+	delta := 1
+	if e.Mod&ModShift != 0 {
+		delta = -1
+	}
+
+	n.MoveFocus(delta, isKeyHandler)
+}
@@ -0,0 +1,117 @@
+package term
+
+import (
+	"image"
+	"sort"
+
+	"zemn.me/reactive/tree"
+)
+
+// A MouseHandler is a Component which handles MouseEvents
+// within a Canvas.
+//
+// A MouseEvent is given first to the deepest MouseHandler whose
+// Canvas it is within, then to the others it is within, shallowest
+// last, until one handles it. Once a MouseHandler handles a button
+// being pressed, it is given every drag and release until the button
+// is released, wherever they are.
+type MouseHandler interface {
+	tree.Component
+
+	// MouseCanvas returns the Canvas MouseEvents are hit-tested
+	// against, usually the one the Component draws to.
+	MouseCanvas() Canvas
+
+	// HandleMouse reports whether the MouseEvent e was handled.
+	// e.Mouse is relative to the Origin of the MouseCanvas.
+	HandleMouse(e Event) (handled bool)
+}
+
+// local returns e relative to the MouseCanvas of h.
+func local(h MouseHandler, e Event) Event {
+	e.Mouse = e.Mouse.Sub(h.MouseCanvas().Origin())
+	return e
+}
+
+// hit returns the Nodes of MouseHandlers in the tree of n
+// whose Canvas contains p, deepest first. Of those as deep,
+// ones later in the tree, which draw over the others, are first.
+func hit(n inputTree, p image.Point) (hits []*tree.Node) {
+	n.Root().Walk(func(n *tree.Node) error {
+		h, ok := n.Component.(MouseHandler)
+		if !ok {
+			return nil
+		}
+
+		c := h.MouseCanvas()
+		if c != nil && p.In(c.Rect().Add(c.Origin())) {
+			hits = append(hits, n)
+		}
+
+		return nil
+	})
+
+	for i, j := 0, len(hits)-1; i < j; i, j = i+1, j-1 {
+		hits[i], hits[j] = hits[j], hits[i]
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Depth() > hits[j].Depth()
+	})
+
+	return
+}
+
+// pressed reports whether e is a
+// button being pressed.
+func pressed(e Event) bool {
+	switch e.Button {
+	case MouseLeft, MouseMiddle, MouseRight:
+		return e.Mod&ModMotion == 0
+	}
+
+	return false
+}
+
+// dragged reports whether e is the mouse
+// moving or released with a button held.
+func dragged(e Event) bool {
+	return e.Button == MouseRelease ||
+		e.Mod&ModMotion != 0 && e.Button != MouseNone
+}
+
+// mouse delivers the MouseEvent e within the tree of n. captured is
+// the Node given drags and releases, if any; mouse returns the Node
+// which should be given them next.
+func mouse(n inputTree, e Event, captured *tree.Node) *tree.Node {
+	if captured != nil && dragged(e) {
+		if h, ok := captured.Component.(MouseHandler); ok {
+			h.HandleMouse(local(h, e))
+
+			if e.Button == MouseRelease {
+				return nil
+			}
+
+			return captured
+		}
+	}
+
+	for _, node := range hit(n, e.Mouse) {
+		h := node.Component.(MouseHandler)
+		if !h.HandleMouse(local(h, e)) {
+			continue
+		}
+
+		if pressed(e) {
+			return node
+		}
+
+		break
+	}
+
+	if pressed(e) {
+		return nil
+	}
+
+	return captured
+}
@@ -0,0 +1,76 @@
+package term
+
+import "sync"
+
+// An EventQueue is the Events channel of a Backend. Events may be
+// sent to it from any goroutine, and it may be closed while they
+// are, so Backends embed it rather than managing a channel
+// themselves. The zero EventQueue is ready to use.
+type EventQueue struct {
+	init   sync.Once
+	events chan Event
+	done   chan struct{}
+
+	// sending is held while sending an Event,
+	// so that Close does not close events
+	// during a send.
+	sending sync.RWMutex
+	closed  sync.Once
+}
+
+func (q *EventQueue) ready() {
+	q.init.Do(func() {
+		q.events = make(chan Event)
+		q.done = make(chan struct{})
+	})
+}
+
+// Events returns the channel Events are sent on,
+// which is closed by Close.
+func (q *EventQueue) Events() <-chan Event {
+	q.ready()
+	return q.events
+}
+
+// Done returns a channel which is closed
+// when the EventQueue is.
+func (q *EventQueue) Done() <-chan struct{} {
+	q.ready()
+	return q.done
+}
+
+// Send sends e on the Events channel, waiting until it is
+// received, unless the EventQueue is closed first.
+func (q *EventQueue) Send(e Event) {
+	q.ready()
+
+	q.sending.RLock()
+	defer q.sending.RUnlock()
+
+	select {
+	case <-q.done:
+		return
+	default:
+	}
+
+	select {
+	case q.events <- e:
+	case <-q.done:
+	}
+}
+
+// Close closes the Events channel, once any sends in
+// progress have given up. Closing it again does nothing.
+func (q *EventQueue) Close() error {
+	q.ready()
+
+	q.closed.Do(func() {
+		close(q.done)
+
+		q.sending.Lock()
+		close(q.events)
+		q.sending.Unlock()
+	})
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package term_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "zemn.me/term"
+)
+
+var _ = Describe("EventQueue", func() {
+	var q *EventQueue
+
+	BeforeEach(func() { q = new(EventQueue) })
+
+	It("should deliver what is sent", func(done Done) {
+		defer close(done)
+
+		go q.Send(Event{Type: KeyEvent, Ch: 'x'})
+		Expect((<-q.Events()).Ch).To(Equal('x'))
+	})
+
+	It("should give up sending when closed", func(done Done) {
+		defer close(done)
+
+		sent := make(chan bool)
+		go func() {
+			q.Send(Event{Type: KeyEvent})
+			close(sent)
+		}()
+
+		Expect(q.Close()).To(Succeed())
+		Eventually(sent).Should(BeClosed())
+		Expect(q.Events()).To(BeClosed())
+		Expect(q.Done()).To(BeClosed())
+	})
+
+	It("should only close once", func() {
+		Expect(q.Close()).To(Succeed())
+		Expect(q.Close()).To(Succeed())
+	})
+})
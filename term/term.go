@@ -2,32 +2,34 @@
 //output
 package term // import "zemn.me/term"
 
+//go:generate go run zemn.me/reactive/cmd/componentgen
+
 import (
 	"image"
 
-	"github.com/nsf/termbox-go"
+	"zemn.me/debug"
 	"zemn.me/reactive"
 	"zemn.me/reactive/tree"
 )
 
-type Attribute = termbox.Attribute
-type Cell = termbox.Cell
-
 var _ reactive.Component = &Term{}
 
 type Term struct {
 	RenderFunc func(Canvas) (components []tree.Component, err error)
 	Canvas
-	stop chan bool
+	Backend Backend
+	stop    chan bool
 }
 
-func New(render func(Canvas) (components []tree.Component, err error)) (term *Term, err error) {
-	err = termbox.Init()
+// New initialises the Backend b, returning a Term which
+// draws to it. Closing the Term closes b.
+func New(b Backend, render func(Canvas) (components []tree.Component, err error)) (term *Term, err error) {
+	err = b.Init()
 	if err != nil {
 		return
 	}
 
-	return &Term{RenderFunc: render, Canvas: newRootCanvas()}, nil
+	return &Term{RenderFunc: render, Backend: b, Canvas: newRootCanvas(b)}, nil
 }
 
 func (Term) Name() string { return "term" }
@@ -40,16 +42,77 @@ func (t Term) ShouldUpdate(old tree.Component) (bool, error) {
 	return false, nil
 }
 func (t Term) Render() ([]tree.Component, error) { return t.RenderFunc(t.Canvas) }
-func (t Term) Close()                            { close(t.stop) }
+func (t Term) Close() {
+	if t.stop != nil {
+		close(t.stop)
+	}
+
+	t.Backend.Close()
+}
+
+// An inputTree is what a Term needs of the StateController it
+// is Mounted with to deliver input to its Components, which the
+// Nodes of a tree provide. Input is delivered through Do, so that
+// it does not race with updates of the tree.
+type inputTree interface {
+	tree.StateController
+	Do(f func())
+	Root() *tree.Node
+	Bubble(handle func(*tree.Node) bool) bool
+	MoveFocus(delta int, focusable func(*tree.Node) bool) *tree.Node
+}
+
+var _ inputTree = &tree.Node{}
+
 func (t *Term) Mount(s tree.StateController) {
+	t.stop = make(chan bool)
+	stop, events := t.stop, t.Backend.Events()
+
+	// without an inputTree, only
+	// resizes can be handled.
+	n, ok := s.(inputTree)
+	if !ok {
+		debug.Log("term: %T cannot deliver input; only resizes will be handled", s)
+	}
+
+	do := func(f func()) {
+		if ok {
+			n.Do(f)
+			return
+		}
+
+		f()
+	}
+
+	// the Node of the MouseHandler given drags
+	// and releases; only used within do.
+	var captured *tree.Node
+
 	go func() {
 		for {
-			// TODO: make this a channel somehow?
-			ev := termbox.PollEvent()
-			switch ev.Type {
-			case termbox.EventResize:
-				t.Canvas = newRootCanvas()
-				s.Update()
+			select {
+			case <-stop:
+				return
+			case ev, open := <-events:
+				if !open {
+					return
+				}
+
+				switch ev.Type {
+				case ResizeEvent:
+					do(func() {
+						t.Canvas = newRootCanvas(t.Backend)
+						tree.UpdatePriority(s, tree.InputPriority)
+					})
+				case KeyEvent:
+					if ok {
+						do(func() { key(n, ev) })
+					}
+				case MouseEvent:
+					if ok {
+						do(func() { captured = mouse(n, ev, captured) })
+					}
+				}
 			}
 		}
 	}()
@@ -61,10 +124,15 @@ type Canvas interface {
 	Buffer() [][]Cell
 	Canvas(image.Rectangle) Canvas
 	ShouldUpdate(c Canvas) bool
+
+	// Origin returns where the top left of
+	// the Canvas is on the screen.
+	Origin() image.Point
 }
 
 type rootCanvas struct {
-	Cells [][]Cell
+	Cells         [][]Cell
+	Width, Height int
 }
 
 func (r rootCanvas) ShouldUpdate(c Canvas) bool {
@@ -73,20 +141,31 @@ func (r rootCanvas) ShouldUpdate(c Canvas) bool {
 		(len(a.Cells) > 0 && (len(a.Cells[0]) != len(b.Cells[0])))
 }
 
+// String describes the canvas by its bounds, so that
+// snapshots of Components drawing to it stay readable.
+func (c rootCanvas) String() string { return "root canvas " + c.Rect().String() }
+
+func (rootCanvas) Origin() image.Point { return image.Point{} }
+
 func (c rootCanvas) Rect() image.Rectangle {
-	w, h := termbox.Size()
 	return image.Rect(
 		0, 0,
-		w, h,
+		c.Width, c.Height,
 	)
 }
 
 func (c rootCanvas) SetCell(pos image.Point, cell Cell) {
-	termbox.SetCell(pos.X, pos.Y, cell.Ch, cell.Fg, cell.Bg)
+	// as termbox did, ignore cells off the screen
+	if pos.Y < 0 || pos.Y >= len(c.Cells) || pos.X < 0 || pos.X >= len(c.Cells[pos.Y]) {
+		return
+	}
+
+	c.Cells[pos.Y][pos.X] = cell
 }
 func (c rootCanvas) Buffer() [][]Cell { return c.Cells }
 func (c rootCanvas) Canvas(r image.Rectangle) Canvas {
 	cn := canvas{}
+	cn.Offset = r.Min
 	cn.Width = r.Dx()
 	cn.Height = r.Dy()
 
@@ -103,15 +182,22 @@ func (c rootCanvas) Canvas(r image.Rectangle) Canvas {
 type canvas struct {
 	Cells         [][]Cell
 	Width, Height int
+
+	// Offset is the Origin of the canvas.
+	Offset image.Point
 }
 
 func (c canvas) ShouldUpdate(c2 Canvas) bool {
 	a, b := c, c2.(canvas)
-	return a.Width != b.Width || a.Height != b.Height
+	return a.Width != b.Width || a.Height != b.Height || a.Offset != b.Offset
 }
 
+func (c canvas) Origin() image.Point { return c.Offset }
+
 func (c canvas) Buffer() [][]Cell { return c.Cells }
 
+func (c canvas) String() string { return "canvas " + c.Rect().Add(c.Offset).String() }
+
 func (c canvas) Rect() image.Rectangle {
 	return image.Rect(
 		0, 0,
@@ -125,6 +211,7 @@ func (c canvas) SetCell(pos image.Point, cell Cell) {
 
 func (c canvas) Canvas(r image.Rectangle) Canvas {
 	cn := canvas{}
+	cn.Offset = c.Offset.Add(r.Min)
 	cn.Width = r.Dx()
 	cn.Height = r.Dy()
 
@@ -138,9 +225,17 @@ func (c canvas) Canvas(r image.Rectangle) Canvas {
 	return cn
 }
 
-func newRootCanvas() (c rootCanvas) {
-	cells := termbox.CellBuffer()
-	width, height := termbox.Size()
+func newRootCanvas(b Backend) (c rootCanvas) {
+	cells := b.CellBuffer()
+	width, height := b.Size()
+
+	// the size may have changed again
+	// since the buffer was allocated
+	if width*height > len(cells) {
+		width, height = 0, 0
+	}
+
+	c.Width, c.Height = width, height
 	for y := 0; y < height; y++ {
 		start := y * width
 		end := y*width + width
@@ -150,8 +245,7 @@ func newRootCanvas() (c rootCanvas) {
 	return
 }
 
-var _ tree.Component = LoadingBar{}
-
+//componentgen
 type LoadingBar struct {
 	Fill     rune
 	Empty    rune
@@ -159,20 +253,7 @@ type LoadingBar struct {
 	Canvas
 }
 
-func (LoadingBar) Mount(tree.StateController) {}
-func (LoadingBar) Close()                     {}
-func (l LoadingBar) ShouldUpdate(old tree.Component) (should bool, err error) {
-	a, b := l, old.(LoadingBar)
-	if a.Canvas.ShouldUpdate(b.Canvas) {
-		return true, nil
-	}
-	a.Canvas, b.Canvas = nil, nil
-	should = a != b
-	return
-}
-func (LoadingBar) Name() string { return "LoadingBar" }
 func (l LoadingBar) Render() (children []tree.Component, err error) {
-	termbox.Flush()
 	c := l.Canvas
 
 	/*
@@ -206,22 +287,12 @@ func (l LoadingBar) Render() (children []tree.Component, err error) {
 	return
 }
 
+//componentgen fill
 type Fill struct {
 	Cell
 	Canvas
 }
 
-func (Fill) Close()                     {}
-func (Fill) Mount(tree.StateController) {}
-func (f Fill) ShouldUpdate(old tree.Component) (should bool, err error) {
-	a, b := f, old.(Fill)
-	if a.Canvas.ShouldUpdate(b.Canvas) {
-		return true, nil
-	}
-	a.Canvas, b.Canvas = nil, nil
-	return a != b, nil
-}
-func (Fill) Name() string { return "fill" }
 func (f Fill) Render() (_ []tree.Component, err error) {
 	c := f.Canvas
 	rows := c.Buffer()
@@ -234,24 +305,12 @@ func (f Fill) Render() (_ []tree.Component, err error) {
 	return
 }
 
-var _ reactive.Component = Text{}
-
+//componentgen text
 type Text struct {
 	Text string
 	Canvas
 }
 
-func (Text) Name() string { return "text" }
-func (t Text) ShouldUpdate(old tree.Component) (bool, error) {
-	a, b := t, old.(Text)
-	if a.Canvas.ShouldUpdate(b.Canvas) {
-		return true, nil
-	}
-	a.Canvas, b.Canvas = nil, nil
-	return a != b, nil
-}
-func (t Text) Close()                     {}
-func (t Text) Mount(tree.StateController) {}
 func (f Text) Render() (_ []tree.Component, err error) {
 	c := f.Canvas
 	runes := []rune(f.Text)
@@ -1,35 +1,48 @@
 package term_test
 
 import (
+	"image"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"zemn.me/reactive"
+	"zemn.me/reactive/tree"
 	. "zemn.me/term"
 	termtest "zemn.me/term/termtest"
 )
 
-var _ = Describe("WithCanvas", func() {
+var _ = Describe("Term", func() {
 	It("should implement reactive.Component", func() {
-		var _ reactive.Component = WithCanvas{}
+		var _ reactive.Component = &Term{}
+	})
+
+	It("should ignore cells set off the screen", func() {
+		t, err := New(termtest.NewScreen(2, 2), func(Canvas) ([]tree.Component, error) { return nil, nil })
+		Expect(err).ToNot(HaveOccurred())
+		defer t.Close()
+
+		for _, p := range []image.Point{{-1, 0}, {0, -1}, {2, 0}, {0, 2}} {
+			Expect(func() { t.Canvas.SetCell(p, Cell{Ch: 'x'}) }).ToNot(Panic())
+		}
 	})
 })
 
 var _ = Describe("Text", func() {
-	It("should implement term.Component", func(done Done) {
+	It("should implement reactive.Component", func(done Done) {
 		defer close(done)
-		var _ Component = Text("hi!")
+		var _ reactive.Component = Text{Text: "hi!"}
 	})
 
 	When("rendered", func() {
 		It("should fill the buffer with text sequentially", func(done Done) {
 			defer close(done)
-			const text Text = "hello world!"
+			const text = "hello world!"
 			const w = 2
 			const h = (len(text) / 2) + 2
 			c := termtest.NewCanvas(w, h)
 
-			children, err := text.Render(c)
+			children, err := Text{Text: text, Canvas: c}.Render()
 			Expect(len(children)).To(Equal(0))
 			Expect(err).ToNot(HaveOccurred())
 
@@ -41,12 +54,12 @@ var _ = Describe("Text", func() {
 
 		It("should truncate upon overflow", func(done Done) {
 			defer close(done)
-			const text Text = "hello world!"
+			const text = "hello world!"
 			const w = 2
 			const h = 2
 			c := termtest.NewCanvas(w, h)
 
-			children, err := text.Render(c)
+			children, err := Text{Text: text, Canvas: c}.Render()
 			Expect(len(children)).To(Equal(0))
 			Expect(err).ToNot(HaveOccurred())
 
@@ -62,16 +75,17 @@ var _ = Describe("Text", func() {
 })
 
 var _ = Describe("LoadingBar", func() {
-	It("should implement term.Component", func(done Done) {
+	It("should implement reactive.Component", func(done Done) {
 		defer close(done)
-		var _ Component = LoadingBar{}
+		var _ reactive.Component = LoadingBar{}
 	})
 
 	When("rendered as half done", func() {
 		It("should produce two children", func(done Done) {
 			defer close(done)
-			c := termtest.NewCanvas(2, 2)
-			children, err := termtest.TestBar.Render(c)
+			bar := termtest.TestBar
+			bar.Canvas = termtest.NewCanvas(2, 2)
+			children, err := bar.Render()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(children)).To(Equal(2))
 		})
@@ -81,14 +95,14 @@ var _ = Describe("LoadingBar", func() {
 var _ = Describe("Fill", func() {
 	It("should implement interfaces correctly", func(done Done) {
 		defer close(done)
-		var _ Component = Fill{}
+		var _ reactive.Component = Fill{}
 	})
 
 	When("rendered", func() {
 		It("should have no children", func(done Done) {
 			defer close(done)
 			c := termtest.NewCanvas(20, 30)
-			children, err := Fill(termtest.TestCell).Render(c)
+			children, err := Fill{Cell: termtest.TestCell, Canvas: c}.Render()
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(children)).To(Equal(0))
 		})
@@ -96,7 +110,7 @@ var _ = Describe("Fill", func() {
 		It("should fill a canvas", func(done Done) {
 			defer close(done)
 			c := termtest.NewCanvas(20, 30)
-			Fill(termtest.TestCell).Render(c)
+			Fill{Cell: termtest.TestCell, Canvas: c}.Render()
 			for _, cell := range c.Base {
 				Expect(cell).To(Equal(termtest.TestCell))
 			}
@@ -0,0 +1,168 @@
+package term
+
+import (
+	"image"
+	"sync"
+
+	"github.com/nsf/termbox-go"
+)
+
+var _ Backend = &Termbox{}
+
+// Termbox is a Backend drawing with github.com/nsf/termbox-go.
+// Since termbox is global, only one may be in use at a time.
+type Termbox struct {
+	mu            sync.Mutex
+	cells         []Cell
+	width, height int
+
+	EventQueue
+	once sync.Once
+}
+
+func (t *Termbox) Init() (err error) {
+	if err = termbox.Init(); err != nil {
+		return
+	}
+
+	termbox.SetInputMode(termbox.InputEsc | termbox.InputMouse)
+
+	go t.poll()
+
+	return
+}
+
+func (t *Termbox) poll() {
+	for {
+		ev := termbox.PollEvent()
+
+		select {
+		case <-t.Done():
+			return
+		default:
+		}
+
+		if e, ok := fromTermbox(ev); ok {
+			t.Send(e)
+		}
+	}
+}
+
+func (t *Termbox) Size() (width, height int) { return termbox.Size() }
+
+func (t *Termbox) CellBuffer() []Cell {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if w, h := termbox.Size(); w != t.width || h != t.height {
+		t.width, t.height = w, h
+		t.cells = make([]Cell, w*h)
+	}
+
+	return t.cells
+}
+
+func (t *Termbox) Flush() error {
+	t.mu.Lock()
+	for i, c := range t.cells {
+		termbox.SetCell(i%t.width, i/t.width, c.Ch, termbox.Attribute(c.Fg), termbox.Attribute(c.Bg))
+	}
+	t.mu.Unlock()
+
+	return termbox.Flush()
+}
+
+func (t *Termbox) Close() error {
+	t.once.Do(func() {
+		t.EventQueue.Close()
+		termbox.Interrupt()
+		termbox.Close()
+	})
+
+	return nil
+}
+
+var termboxKeys = map[termbox.Key]Key{
+	termbox.KeyEnter:      KeyEnter,
+	termbox.KeyTab:        KeyTab,
+	termbox.KeyBackspace:  KeyBackspace,
+	termbox.KeyBackspace2: KeyBackspace,
+	termbox.KeyEsc:        KeyEsc,
+	termbox.KeyArrowUp:    KeyUp,
+	termbox.KeyArrowDown:  KeyDown,
+	termbox.KeyArrowLeft:  KeyLeft,
+	termbox.KeyArrowRight: KeyRight,
+	termbox.KeyHome:       KeyHome,
+	termbox.KeyEnd:        KeyEnd,
+	termbox.KeyPgup:       KeyPgUp,
+	termbox.KeyPgdn:       KeyPgDn,
+	termbox.KeyInsert:     KeyInsert,
+	termbox.KeyDelete:     KeyDelete,
+	termbox.KeyF1:         KeyF1,
+	termbox.KeyF2:         KeyF2,
+	termbox.KeyF3:         KeyF3,
+	termbox.KeyF4:         KeyF4,
+	termbox.KeyF5:         KeyF5,
+	termbox.KeyF6:         KeyF6,
+	termbox.KeyF7:         KeyF7,
+	termbox.KeyF8:         KeyF8,
+	termbox.KeyF9:         KeyF9,
+	termbox.KeyF10:        KeyF10,
+	termbox.KeyF11:        KeyF11,
+	termbox.KeyF12:        KeyF12,
+}
+
+var termboxButtons = map[termbox.Key]Button{
+	termbox.MouseLeft:      MouseLeft,
+	termbox.MouseMiddle:    MouseMiddle,
+	termbox.MouseRight:     MouseRight,
+	termbox.MouseRelease:   MouseRelease,
+	termbox.MouseWheelUp:   MouseWheelUp,
+	termbox.MouseWheelDown: MouseWheelDown,
+}
+
+// fromTermbox converts a termbox Event, reporting
+// false if it has no equivalent.
+func fromTermbox(ev termbox.Event) (e Event, ok bool) {
+	if ev.Mod&termbox.ModAlt != 0 {
+		e.Mod |= ModAlt
+	}
+
+	switch ev.Type {
+	case termbox.EventKey:
+		e.Type = KeyEvent
+
+		switch key, named := termboxKeys[ev.Key]; {
+		case ev.Ch != 0:
+			e.Ch = ev.Ch
+		case named:
+			e.Key = key
+		case ev.Key == termbox.KeySpace:
+			e.Ch = ' '
+		case ev.Key == termbox.KeyCtrlSpace:
+			e.Ch, e.Mod = ' ', e.Mod|ModCtrl
+		case ev.Key <= termbox.KeyCtrlZ:
+			e.Ch, e.Mod = 'a'+rune(ev.Key)-1, e.Mod|ModCtrl
+		default:
+			return e, false
+		}
+	case termbox.EventMouse:
+		e.Type = MouseEvent
+		e.Mouse = image.Pt(ev.MouseX, ev.MouseY)
+		e.Button = termboxButtons[ev.Key]
+
+		if ev.Mod&termbox.ModMotion != 0 {
+			e.Mod |= ModMotion
+		}
+	case termbox.EventResize:
+		e.Type = ResizeEvent
+		e.Width, e.Height = ev.Width, ev.Height
+	case termbox.EventError:
+		e.Type = ErrorEvent
+		e.Err = ev.Err
+	default:
+		return e, false
+	}
+
+	return e, true
+}
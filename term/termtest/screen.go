@@ -0,0 +1,201 @@
+package termtest
+
+import (
+	"image"
+	"strings"
+	"sync"
+
+	"zemn.me/reactive/tree"
+	"zemn.me/term"
+)
+
+var (
+	_ term.Backend   = &Screen{}
+	_ tree.Committer = &Screen{}
+)
+
+// A Screen is a term.Backend with no terminal behind it, so that
+// a whole term.Term can be run in a test. Events are sent to it by
+// the test, and a Frame is captured each time it is flushed.
+//
+// A Screen is also a tree.Committer which flushes after each
+// update, and records errors, so it can be given to NewNode.
+type Screen struct {
+	mu            sync.Mutex
+	width, height int
+	cells         []term.Cell
+	frames        []Frame
+	errs          []error
+	closed        bool
+
+	term.EventQueue
+}
+
+// NewScreen returns a Screen of the given size.
+func NewScreen(width, height int) *Screen {
+	return &Screen{
+		width:  width,
+		height: height,
+		cells:  make([]term.Cell, width*height),
+	}
+}
+
+func (s *Screen) Init() error { return nil }
+
+func (s *Screen) Size() (width, height int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.width, s.height
+}
+
+func (s *Screen) CellBuffer() []term.Cell {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cells
+}
+
+// Flush captures a Frame of what has been drawn.
+func (s *Screen) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := Frame{
+		Width:  s.width,
+		Height: s.height,
+		Cells:  make([]term.Cell, len(s.cells)),
+	}
+
+	copy(f.Cells, s.cells)
+	s.frames = append(s.frames, f)
+
+	return nil
+}
+
+// Close closes the Events channel.
+func (s *Screen) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	return s.EventQueue.Close()
+}
+
+// Closed reports whether the Screen has been closed.
+func (s *Screen) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// Resize changes the size of the Screen, sending a ResizeEvent.
+func (s *Screen) Resize(width, height int) {
+	s.mu.Lock()
+	s.width, s.height = width, height
+	s.cells = make([]term.Cell, width*height)
+	s.mu.Unlock()
+
+	s.Send(term.Event{Type: term.ResizeEvent, Width: width, Height: height})
+}
+
+// Key sends a KeyEvent for k.
+func (s *Screen) Key(k term.Key, mod term.Modifier) {
+	s.Send(term.Event{Type: term.KeyEvent, Key: k, Mod: mod})
+}
+
+// Type sends a KeyEvent for each character of text.
+func (s *Screen) Type(text string) {
+	for _, ch := range text {
+		s.Send(term.Event{Type: term.KeyEvent, Ch: ch})
+	}
+}
+
+// Click sends a MouseEvent for b at x, y followed by its release.
+func (s *Screen) Click(b term.Button, x, y int) {
+	s.Send(term.Event{Type: term.MouseEvent, Button: b, Mouse: image.Pt(x, y)})
+	s.Send(term.Event{Type: term.MouseEvent, Button: term.MouseRelease, Mouse: image.Pt(x, y)})
+}
+
+// Frames returns every Frame captured so far, oldest first.
+func (s *Screen) Frames() []Frame {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Frame(nil), s.frames...)
+}
+
+// Frame returns the last Frame captured, which
+// is empty if the Screen has not been flushed.
+func (s *Screen) Frame() Frame {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.frames) == 0 {
+		return Frame{}
+	}
+
+	return s.frames[len(s.frames)-1]
+}
+
+// Text returns the String of the last Frame,
+// so it can be polled with Eventually.
+func (s *Screen) Text() string { return s.Frame().String() }
+
+func (*Screen) Map(tree.Component)   {}
+func (*Screen) UnMap(tree.Component) {}
+
+// Commit flushes the Screen.
+func (s *Screen) Commit() { s.Flush() }
+
+// Error records err.
+func (s *Screen) Error(c tree.Component, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errs = append(s.errs, err)
+}
+
+// Errors returns the errors passed to Error.
+func (s *Screen) Errors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]error(nil), s.errs...)
+}
+
+// A Frame is what was on a Screen when it was flushed.
+type Frame struct {
+	Width, Height int
+	Cells         []term.Cell
+}
+
+// At returns the cell at x, y.
+func (f Frame) At(x, y int) term.Cell { return f.Cells[y*f.Width+x] }
+
+// Line returns the characters of row y, with
+// empty cells as spaces.
+func (f Frame) Line(y int) string {
+	var b strings.Builder
+	for _, c := range f.Cells[y*f.Width : (y+1)*f.Width] {
+		if c.Ch == 0 {
+			c.Ch = ' '
+		}
+
+		b.WriteRune(c.Ch)
+	}
+
+	return b.String()
+}
+
+// String returns the Lines of the Frame, without
+// trailing spaces, separated by newlines.
+func (f Frame) String() string {
+	lines := make([]string, f.Height)
+	for y := range lines {
+		lines[y] = strings.TrimRight(f.Line(y), " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,107 @@
+package termtest_test
+
+import (
+	"fmt"
+	"image"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive/tree"
+	"zemn.me/term"
+	. "zemn.me/term/termtest"
+)
+
+var _ = Describe("Screen", func() {
+	var screen *Screen
+
+	BeforeEach(func() { screen = NewScreen(3, 2) })
+
+	It("should implement zemn.me/term.Backend", func() {
+		var _ term.Backend = screen
+	})
+
+	It("should capture a Frame each flush", func() {
+		screen.CellBuffer()[1] = term.Cell{Ch: 'a', Fg: term.ColorRed}
+		Expect(screen.Flush()).To(Succeed())
+
+		screen.CellBuffer()[4] = term.Cell{Ch: 'b'}
+		Expect(screen.Flush()).To(Succeed())
+
+		frames := screen.Frames()
+		Expect(frames).To(HaveLen(2))
+		Expect(frames[0].String()).To(Equal(" a\n"))
+		Expect(frames[0].At(1, 0)).To(Equal(term.Cell{Ch: 'a', Fg: term.ColorRed}))
+		Expect(frames[1].Line(1)).To(Equal(" b "))
+		Expect(screen.Frame()).To(Equal(frames[1]))
+	})
+
+	It("should not change captured Frames when drawn to", func() {
+		screen.Flush()
+		screen.CellBuffer()[0] = term.Cell{Ch: 'a'}
+		Expect(screen.Frame().At(0, 0)).To(Equal(term.Cell{}))
+	})
+
+	It("should send Events until closed", func() {
+		go screen.Type("hi")
+		Eventually(screen.Events()).Should(Receive(Equal(term.Event{Type: term.KeyEvent, Ch: 'h'})))
+		Eventually(screen.Events()).Should(Receive(Equal(term.Event{Type: term.KeyEvent, Ch: 'i'})))
+
+		Expect(screen.Close()).To(Succeed())
+		Expect(screen.Events()).To(BeClosed())
+		screen.Key(term.KeyEnter, 0)
+	})
+
+	It("should reallocate its cells when resized", func() {
+		go screen.Resize(4, 1)
+		Eventually(screen.Events()).Should(Receive(Equal(term.Event{Type: term.ResizeEvent, Width: 4, Height: 1})))
+
+		w, h := screen.Size()
+		Expect([]int{w, h}).To(Equal([]int{4, 1}))
+		Expect(screen.CellBuffer()).To(HaveLen(4))
+	})
+
+	When("running a Term", func() {
+		var (
+			s *tree.Scheduler
+			n *tree.Node
+		)
+
+		BeforeEach(func() {
+			screen = NewScreen(5, 2)
+			t, err := term.New(screen, func(c term.Canvas) ([]tree.Component, error) {
+				r := c.Rect()
+				return []tree.Component{
+					term.Text{
+						Canvas: c.Canvas(image.Rect(0, 0, r.Dx(), 1)),
+						Text:   fmt.Sprintf("%dx%d", r.Dx(), r.Dy()),
+					},
+				}, nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			s = tree.NewScheduler()
+			n = s.NewNode(t, screen)
+			s.Flush()
+		})
+
+		It("should draw it", func() {
+			Expect(screen.Text()).To(Equal("5x2\n"))
+			Expect(screen.Errors()).To(BeEmpty())
+		})
+
+		It("should redraw it when resized", func() {
+			screen.Resize(4, 1)
+
+			Eventually(func() string {
+				s.Flush()
+				return screen.Text()
+			}).Should(Equal("4x1"))
+		})
+
+		It("should be closed when the Term is unmounted", func() {
+			n.Unmount()
+			Expect(screen.Closed()).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,213 @@
+package termtest_test
+
+import (
+	"fmt"
+	"image"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"zemn.me/reactive/tree"
+	"zemn.me/term"
+	. "zemn.me/term/termtest"
+)
+
+// handled is a log of the Events
+// handled by each component.
+type handled struct {
+	sync.Mutex
+	events []string
+}
+
+func (h *handled) add(format string, args ...interface{}) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.events = append(h.events, fmt.Sprintf(format, args...))
+}
+
+func (h *handled) get() []string {
+	h.Lock()
+	defer h.Unlock()
+
+	return append([]string(nil), h.events...)
+}
+
+// handler is a KeyHandler which handles the
+// keys of its runes, and has children.
+type handler struct {
+	id       string
+	runes    string
+	log      *handled
+	children []tree.Component
+	s        tree.StateController
+}
+
+var _ term.KeyHandler = &handler{}
+
+func (h *handler) Name() string                              { return h.id }
+func (h *handler) Mount(s tree.StateController)              { h.s = s }
+func (h *handler) Close()                                    {}
+func (h *handler) ShouldUpdate(tree.Component) (bool, error) { return false, nil }
+func (h *handler) Render() ([]tree.Component, error)         { return h.children, nil }
+
+func (h *handler) HandleKey(e term.Event) bool {
+	for _, r := range h.runes {
+		if e.Ch == r {
+			h.log.add("%s %c", h.id, r)
+			return true
+		}
+	}
+
+	return false
+}
+
+// area is a MouseHandler of a Canvas,
+// which handles events if handles is true.
+type area struct {
+	id       string
+	canvas   term.Canvas
+	handles  bool
+	log      *handled
+	children []tree.Component
+}
+
+var _ term.MouseHandler = &area{}
+
+func (a *area) Name() string                              { return a.id }
+func (a *area) Mount(tree.StateController)                {}
+func (a *area) Close()                                    {}
+func (a *area) ShouldUpdate(tree.Component) (bool, error) { return false, nil }
+func (a *area) Render() ([]tree.Component, error)         { return a.children, nil }
+func (a *area) MouseCanvas() term.Canvas                  { return a.canvas }
+
+func (a *area) HandleMouse(e term.Event) bool {
+	if a.handles {
+		a.log.add("%s %d %d,%d", a.id, e.Button, e.Mouse.X, e.Mouse.Y)
+	}
+
+	return a.handles
+}
+
+var _ = Describe("Term", func() {
+	var (
+		screen      *Screen
+		s           *tree.Scheduler
+		n           *tree.Node
+		log         *handled
+		panel, a, b *handler
+		logged      func() []string
+		mount       func(render func(c term.Canvas) ([]tree.Component, error))
+	)
+
+	// input is delivered on the goroutine
+	// updating the tree, which is this one.
+	logged = func() []string {
+		s.Flush()
+		return log.get()
+	}
+
+	// mount mounts a Term rendering with render
+	// on a new Screen.
+	mount = func(render func(c term.Canvas) ([]tree.Component, error)) {
+		screen = NewScreen(5, 2)
+		t, err := term.New(screen, render)
+		Expect(err).ToNot(HaveOccurred())
+
+		s = tree.NewScheduler()
+		n = s.NewNode(t, screen)
+		s.Flush()
+	}
+
+	BeforeEach(func() {
+		log = new(handled)
+	})
+
+	AfterEach(func() {
+		// closing the Term closes the Screen
+		n.Unmount()
+		Expect(screen.Closed()).To(BeTrue())
+		Expect(screen.Close()).To(Succeed())
+	})
+
+	Describe("keys", func() {
+		BeforeEach(func() {
+			a = &handler{id: "a", runes: "x", log: log}
+			b = &handler{id: "b", runes: "x", log: log}
+			panel = &handler{id: "panel", runes: "q", log: log, children: []tree.Component{a, b}}
+
+			mount(func(term.Canvas) ([]tree.Component, error) {
+				return []tree.Component{panel}, nil
+			})
+		})
+
+		It("should go only to the root when nothing has focus", func() {
+			screen.Type("xq")
+			Consistently(logged).Should(BeEmpty())
+		})
+
+		It("should go to the Component with focus, then its ancestors", func() {
+			a.s.(tree.Focuser).Focus()
+
+			screen.Type("xq")
+			Eventually(logged).Should(Equal([]string{"a x", "panel q"}))
+		})
+
+		It("should move focus with Tab and Shift-Tab", func() {
+			a.s.(tree.Focuser).Focus()
+
+			screen.Key(term.KeyTab, 0)
+			screen.Type("x")
+			Eventually(logged).Should(Equal([]string{"b x"}))
+
+			screen.Key(term.KeyTab, term.ModShift)
+			screen.Type("x")
+			Eventually(logged).Should(Equal([]string{"b x", "a x"}))
+
+			screen.Key(term.KeyTab, term.ModShift)
+			screen.Type("xq")
+			Eventually(logged).Should(Equal([]string{"b x", "a x", "panel q"}))
+		})
+	})
+
+	Describe("mouse", func() {
+		var outer, inner *area
+
+		BeforeEach(func() {
+			mount(func(c term.Canvas) ([]tree.Component, error) {
+				inner = &area{id: "inner", handles: true, log: log, canvas: c.Canvas(image.Rect(2, 1, 4, 2))}
+				outer = &area{id: "outer", handles: true, log: log, canvas: c, children: []tree.Component{inner}}
+				return []tree.Component{outer}, nil
+			})
+		})
+
+		It("should go to the deepest MouseHandler hit, in its coordinates", func() {
+			screen.Click(term.MouseLeft, 3, 1)
+			Eventually(logged).Should(Equal([]string{"inner 1 1,0", "inner 4 1,0"}))
+		})
+
+		It("should go to those it is within only", func() {
+			screen.Click(term.MouseRight, 1, 1)
+			Eventually(logged).Should(Equal([]string{"outer 3 1,1", "outer 4 1,1"}))
+		})
+
+		It("should go to shallower MouseHandlers if not handled", func() {
+			inner.handles = false
+			screen.Send(term.Event{Type: term.MouseEvent, Button: term.MouseWheelUp, Mouse: image.Pt(3, 1)})
+			Eventually(logged).Should(Equal([]string{"outer 5 3,1"}))
+		})
+
+		It("should give drags and the release to the MouseHandler pressed", func() {
+			screen.Send(term.Event{Type: term.MouseEvent, Button: term.MouseLeft, Mouse: image.Pt(2, 1)})
+			screen.Send(term.Event{Type: term.MouseEvent, Button: term.MouseLeft, Mod: term.ModMotion, Mouse: image.Pt(0, 0)})
+			screen.Send(term.Event{Type: term.MouseEvent, Button: term.MouseRelease, Mouse: image.Pt(0, 0)})
+			screen.Click(term.MouseLeft, 0, 0)
+
+			Eventually(logged).Should(Equal([]string{
+				"inner 1 0,0", "inner 1 -2,-1", "inner 4 -2,-1",
+				"outer 1 0,0", "outer 4 0,0",
+			}))
+		})
+	})
+})
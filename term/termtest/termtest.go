@@ -40,10 +40,22 @@ type Canvas struct {
 	Base          []term.Cell
 	Cells         [][]term.Cell
 	Width, Height int
+
+	// Offset is the Origin of the Canvas.
+	Offset image.Point
 }
 
 func (c Canvas) Buffer() [][]term.Cell { return c.Cells }
 
+func (c Canvas) ShouldUpdate(c2 term.Canvas) bool {
+	a, b := c, c2.(Canvas)
+	return a.Width != b.Width || a.Height != b.Height || a.Offset != b.Offset
+}
+
+func (c Canvas) Origin() image.Point { return c.Offset }
+
+func (c Canvas) String() string { return "test canvas " + c.Rect().Add(c.Offset).String() }
+
 func (c Canvas) Rect() image.Rectangle {
 	return image.Rect(
 		0, 0,
@@ -58,6 +70,7 @@ func (c Canvas) SetCell(pos image.Point, cell term.Cell) {
 func (c Canvas) Canvas(r image.Rectangle) term.Canvas {
 	cn := Canvas{}
 	cn.Base = c.Base
+	cn.Offset = c.Offset.Add(r.Min)
 	cn.Width = r.Dx()
 	cn.Height = r.Dy()
 
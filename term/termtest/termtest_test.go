@@ -62,6 +62,14 @@ var _ = Describe("Canvas", func() {
 	})
 
 	When("split into a sub-canvas", func() {
+		It("should track its Origin", func() {
+			var c term.Canvas = NewCanvas(10, 10)
+			c = c.Canvas(image.Rect(2, 3, 8, 8)).Canvas(image.Rect(1, 1, 2, 2))
+
+			Expect(c.Origin()).To(Equal(image.Pt(3, 4)))
+			Expect(c.Rect()).To(Equal(image.Rect(0, 0, 1, 1)))
+		})
+
 		It("should have the correct rect size", func(done Done) {
 			defer close(done)
 			const w = 300
@@ -100,14 +108,21 @@ var _ = Describe("Canvas", func() {
 				nw, nh,
 			).Add(image.Pt(dx, dy))).(Canvas)
 
-			for x := 0; x < w; x++ {
-				for y := 0; y < h; y++ {
+			for x := 0; x < nw; x++ {
+				for y := 0; y < nh; y++ {
 					c.SetCell(image.Pt(x, y), TestCell)
 				}
 			}
 
-			for _, cell := range c.Base {
-				Expect(cell).To(Equal(TestCell), "%+v")
+			// only the cells of the sub-canvas
+			// are written to
+			inside := image.Rect(0, 0, nw, nh).Add(image.Pt(dx, dy))
+			for i, cell := range c.Base {
+				if image.Pt(i%w, i/w).In(inside) {
+					Expect(cell).To(Equal(TestCell), "cell %d", i)
+				} else {
+					Expect(cell).To(Equal(term.Cell{}), "cell %d", i)
+				}
 			}
 		})
 	})